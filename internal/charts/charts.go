@@ -11,6 +11,7 @@ import (
 	"github.com/go-echarts/go-echarts/v2/opts"
 	"github.com/go-echarts/go-echarts/v2/templates"
 	"rafaelmartins.com/p/synth-datagen/internal/ctypes"
+	"rafaelmartins.com/p/synth-datagen/internal/renderer"
 )
 
 func init() {
@@ -47,6 +48,11 @@ type Charts struct {
 	page *components.Page
 }
 
+var (
+	_ renderer.Renderer               = (*Charts)(nil)
+	_ renderer.FilterResponseRenderer = (*Charts)(nil)
+)
+
 func New(title string) *Charts {
 	page := components.NewPage().SetLayout(components.PageFlexLayout)
 	page.PageTitle = title
@@ -126,6 +132,9 @@ func (c *Charts) renderStruct(title string, val reflect.Value) {
 }
 
 func (c *Charts) AddData(identifier string, value any, attributes []string, strWidth *int) {
+	if t, ok := value.(renderer.Typed); ok {
+		value = t.Value
+	}
 	if value == nil {
 		return
 	}
@@ -0,0 +1,168 @@
+package charts
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/cmplx"
+	"reflect"
+	"strconv"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const (
+	responseEntries = 8
+	responsePoints  = 256
+)
+
+type responseField struct {
+	name     string
+	feedback bool
+	delay    int
+}
+
+// responseFields parses the `filter` tags of the coefficient struct fields, where `bN` is
+// the feedforward coefficient and `aN` is the feedback coefficient for the sample delayed
+// by N, following the difference equation:
+// y[n] = b0 * x[n] + b1 * x[n-1] + ... + a1 * y[n-1] + a2 * y[n-2] + ...
+func responseFields(typ reflect.Type) []*responseField {
+	rv := []*responseField{}
+	for _, field := range reflect.VisibleFields(typ) {
+		tag, ok := field.Tag.Lookup("filter")
+		if !field.IsExported() || !ok || len(tag) < 2 || (tag[0] != 'a' && tag[0] != 'b') {
+			continue
+		}
+
+		delay, err := strconv.Atoi(tag[1:])
+		if err != nil {
+			continue
+		}
+
+		rv = append(rv, &responseField{
+			name:     field.Name,
+			feedback: tag[0] == 'a',
+			delay:    delay,
+		})
+	}
+	return rv
+}
+
+func response(val reflect.Value, fields []*responseField, scale float64, nfreq float64) complex128 {
+	f64 := reflect.TypeOf(float64(0))
+	num := complex(0, 0)
+	den := complex(1, 0)
+	for _, field := range fields {
+		fval := val.FieldByName(field.name)
+		if !fval.CanConvert(f64) {
+			continue
+		}
+
+		c := complex(fval.Convert(f64).Float()/scale, 0) * cmplx.Exp(complex(0, -2*math.Pi*nfreq*float64(field.delay)))
+		if field.feedback {
+			den -= c
+		} else {
+			num += c
+		}
+	}
+	return num / den
+}
+
+func (c *Charts) getResponseLine(title string, unit string, minFreq float64, maxFreq float64) *charts.Line {
+	rv := c.getLine(title)
+	rv.SetGlobalOptions(
+		charts.WithXAxisOpts(opts.XAxis{
+			Type: "log",
+			Name: "Hz",
+			Min:  minFreq,
+			Max:  maxFreq,
+		}),
+		charts.WithYAxisOpts(opts.YAxis{
+			Type: "value",
+			Name: unit,
+		}),
+		charts.WithLegendOpts(opts.Legend{
+			Show: opts.Bool(true),
+			Top:  "bottom",
+		}),
+	)
+	return rv
+}
+
+func (c *Charts) renderResponse(title string, val reflect.Value, sampleRate float64, scale float64) {
+	if c.page == nil || val.Type().Kind() != reflect.Slice || val.Len() == 0 || sampleRate <= 0 || scale == 0 {
+		return
+	}
+
+	etyp := reflect.TypeOf(val.Index(0).Interface())
+	if etyp.Kind() != reflect.Struct {
+		return
+	}
+
+	fields := responseFields(etyp)
+	if len(fields) == 0 {
+		log.Printf("charts: %s: coefficients without filter tags, response skipped", title)
+		return
+	}
+
+	nyquist := sampleRate / 2
+	minFreq := min(10, nyquist/100)
+
+	freqs := make([]float64, 0, responsePoints)
+	for i := 0.; i < responsePoints; i++ {
+		freqs = append(freqs, minFreq*math.Pow(nyquist/minFreq, i/(responsePoints-1)))
+	}
+
+	indexes := []int{}
+	for i := 0; i < min(val.Len(), responseEntries); i++ {
+		idx := 0
+		if n := min(val.Len(), responseEntries); n > 1 {
+			idx = int(math.Round(float64(i) * float64(val.Len()-1) / float64(n-1)))
+		}
+		indexes = append(indexes, idx)
+	}
+
+	magnitude := c.getResponseLine(title+" (magnitude)", "dB", minFreq, nyquist)
+	phase := c.getResponseLine(title+" (phase)", "degrees", minFreq, nyquist)
+	for _, idx := range indexes {
+		eval := reflect.ValueOf(val.Index(idx).Interface())
+
+		mag := make([]opts.LineData, 0, len(freqs))
+		ph := make([]opts.LineData, 0, len(freqs))
+		for _, freq := range freqs {
+			h := response(eval, fields, scale, freq/sampleRate)
+			mag = append(mag, opts.LineData{Value: []float64{freq, max(20*math.Log10(cmplx.Abs(h)), -120)}})
+			ph = append(ph, opts.LineData{Value: []float64{freq, cmplx.Phase(h) * 180 / math.Pi}})
+		}
+
+		name := fmt.Sprintf("%d", idx)
+		magnitude.AddSeries(name, mag, charts.WithLineChartOpts(opts.LineChart{
+			ShowSymbol: opts.Bool(false),
+		}))
+		phase.AddSeries(name, ph, charts.WithLineChartOpts(opts.LineChart{
+			ShowSymbol: opts.Bool(false),
+		}))
+	}
+	c.page.AddCharts(magnitude, phase)
+}
+
+func (c *Charts) AddFilterResponse(identifier string, value any, sampleRate float64, scale float64) {
+	if value == nil {
+		return
+	}
+
+	val := reflect.ValueOf(value)
+	if val.Kind() != reflect.Slice || val.Len() == 0 {
+		return
+	}
+
+	if reflect.TypeOf(val.Index(0).Interface()).Kind() == reflect.Struct {
+		c.renderResponse(identifier, val, sampleRate, scale)
+		return
+	}
+
+	for i := 0; i < val.Len(); i++ {
+		c.AddFilterResponse(fmt.Sprintf("%s_%d", identifier, i), val.Index(i).Interface(), sampleRate, scale)
+	}
+}
@@ -5,15 +5,18 @@ import (
 	"io"
 	"strings"
 
-	"rafaelmartins.com/p/synth-datagen/internal/codegen/stringify"
 	"rafaelmartins.com/p/synth-datagen/internal/utils"
 )
 
 type data struct {
-	identifier string
-	value      any
-	attributes []string
-	strWidth   *int
+	identifier     string
+	typeIdentifier string
+	structType     string
+	value          any
+	attributes     []string
+	strWidth       *int
+	comments       []string
+	padding        int
 }
 
 type dataList []*data
@@ -27,12 +30,57 @@ func (d *dataList) add(identifier string, value any, attributes []string, strWid
 	})
 }
 
-func (d dataList) write(w io.Writer) error {
+// comment attaches a comment to the last data added with the given identifier, to be
+// written right before its declaration.
+func (d dataList) comment(identifier string, comment string) {
+	for i := len(d) - 1; i >= 0; i-- {
+		if d[i].identifier == identifier {
+			d[i].comments = append(d[i].comments, comment)
+			return
+		}
+	}
+}
+
+// nameStruct sets the struct type name of the last data added with the given identifier.
+func (d dataList) nameStruct(identifier string, structType string) {
+	for i := len(d) - 1; i >= 0; i-- {
+		if d[i].identifier == identifier {
+			d[i].structType = structType
+			return
+		}
+	}
+}
+
+// pad marks the last dimension of the last data added with the given identifier as padded
+// with padding elements, that are not reported by the dimension macros.
+func (d dataList) pad(identifier string, padding int) {
+	for i := len(d) - 1; i >= 0; i-- {
+		if d[i].identifier == identifier {
+			d[i].padding = padding
+			return
+		}
+	}
+}
+
+func (d dataList) write(w io.Writer, types *structTypeList) error {
 	for _, dat := range d {
+		if rows, ok := jaggedRows(dat.value); ok {
+			if err := dat.writeJagged(w, rows, types); err != nil {
+				return err
+			}
+			continue
+		}
+
 		if _, err := fmt.Fprintf(w, "\n"); err != nil {
 			return err
 		}
 
+		for _, c := range dat.comments {
+			if _, err := fmt.Fprintf(w, "// %s\n", c); err != nil {
+				return err
+			}
+		}
+
 		if dat.strWidth != nil {
 			if _, ok := dat.value.(string); ok {
 				if err := utils.ApplyStringWidth(&dat.value, *dat.strWidth); err != nil {
@@ -45,7 +93,11 @@ func (d dataList) write(w io.Writer) error {
 			}
 		}
 
-		value, ctype, dim, err := stringify.Stringify(dat.value)
+		typeIdentifier := dat.identifier
+		if dat.typeIdentifier != "" {
+			typeIdentifier = dat.typeIdentifier
+		}
+		value, ctype, dim, err := types.stringify(typeIdentifier, dat.structType, dat.value)
 		if err != nil {
 			return err
 		}
@@ -70,12 +122,23 @@ func (d dataList) write(w io.Writer) error {
 			return err
 		}
 
+		padded := 0
+		if len(dim) > 0 && dat.padding > 0 {
+			padded = dim[len(dim)-1]
+			dim[len(dim)-1] -= dat.padding
+		}
+
 		switch len(dim) {
 		case 0:
 		case 1:
 			if _, err := fmt.Fprintf(w, "#define %s_len %d\n", dat.identifier, dim[0]); err != nil {
 				return err
 			}
+			if padded > 0 {
+				if _, err := fmt.Fprintf(w, "#define %s_padded_len %d\n", dat.identifier, padded); err != nil {
+					return err
+				}
+			}
 
 		case 2:
 			if _, err := fmt.Fprintf(w, "#define %s_rows %d\n", dat.identifier, dim[0]); err != nil {
@@ -84,6 +147,11 @@ func (d dataList) write(w io.Writer) error {
 			if _, err := fmt.Fprintf(w, "#define %s_cols %d\n", dat.identifier, dim[1]); err != nil {
 				return err
 			}
+			if padded > 0 {
+				if _, err := fmt.Fprintf(w, "#define %s_padded_cols %d\n", dat.identifier, padded); err != nil {
+					return err
+				}
+			}
 
 		default:
 			for i, d := range dim {
@@ -91,6 +159,11 @@ func (d dataList) write(w io.Writer) error {
 					return err
 				}
 			}
+			if padded > 0 {
+				if _, err := fmt.Fprintf(w, "#define %s_padded_len_%d %d\n", dat.identifier, len(dim)-1, padded); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+
+	"rafaelmartins.com/p/synth-datagen/internal/renderer"
 )
 
 func TestDataWrite(t *testing.T) {
@@ -11,7 +13,7 @@ func TestDataWrite(t *testing.T) {
 		var dl dataList
 		dl.add("my_var", int32(42), nil, nil)
 		var buf bytes.Buffer
-		if err := dl.write(&buf); err != nil {
+		if err := dl.write(&buf, nil); err != nil {
 			t.Fatal(err)
 		}
 		expected := "\nstatic const int32_t my_var = 0x0000002a;\n"
@@ -24,7 +26,7 @@ func TestDataWrite(t *testing.T) {
 		var dl dataList
 		dl.add("flag", true, nil, nil)
 		var buf bytes.Buffer
-		if err := dl.write(&buf); err != nil {
+		if err := dl.write(&buf, nil); err != nil {
 			t.Fatal(err)
 		}
 		expected := "\nstatic const bool flag = true;\n"
@@ -37,7 +39,7 @@ func TestDataWrite(t *testing.T) {
 		var dl dataList
 		dl.add("name", "hello", nil, nil)
 		var buf bytes.Buffer
-		if err := dl.write(&buf); err != nil {
+		if err := dl.write(&buf, nil); err != nil {
 			t.Fatal(err)
 		}
 		expected := "\nstatic const char* name = \"hello\";\n"
@@ -50,7 +52,7 @@ func TestDataWrite(t *testing.T) {
 		var dl dataList
 		dl.add("arr", []int32{1, 2, 3}, nil, nil)
 		var buf bytes.Buffer
-		if err := dl.write(&buf); err != nil {
+		if err := dl.write(&buf, nil); err != nil {
 			t.Fatal(err)
 		}
 		got := buf.String()
@@ -66,7 +68,7 @@ func TestDataWrite(t *testing.T) {
 		var dl dataList
 		dl.add("mat", [][]int32{{1, 2}, {3, 4}}, nil, nil)
 		var buf bytes.Buffer
-		if err := dl.write(&buf); err != nil {
+		if err := dl.write(&buf, nil); err != nil {
 			t.Fatal(err)
 		}
 		got := buf.String()
@@ -85,7 +87,7 @@ func TestDataWrite(t *testing.T) {
 		var dl dataList
 		dl.add("cube", [][][]int32{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}}, nil, nil)
 		var buf bytes.Buffer
-		if err := dl.write(&buf); err != nil {
+		if err := dl.write(&buf, nil); err != nil {
 			t.Fatal(err)
 		}
 		got := buf.String()
@@ -107,7 +109,7 @@ func TestDataWrite(t *testing.T) {
 		var dl dataList
 		dl.add("my_var", int32(1), []string{"__attribute__((aligned(4)))"}, nil)
 		var buf bytes.Buffer
-		if err := dl.write(&buf); err != nil {
+		if err := dl.write(&buf, nil); err != nil {
 			t.Fatal(err)
 		}
 		expected := "\nstatic const int32_t my_var __attribute__((aligned(4))) = 0x00000001;\n"
@@ -120,7 +122,7 @@ func TestDataWrite(t *testing.T) {
 		var dl dataList
 		dl.add("my_var", int32(1), []string{"__attr1__", "__attr2__"}, nil)
 		var buf bytes.Buffer
-		if err := dl.write(&buf); err != nil {
+		if err := dl.write(&buf, nil); err != nil {
 			t.Fatal(err)
 		}
 		expected := "\nstatic const int32_t my_var __attr1__ __attr2__ = 0x00000001;\n"
@@ -133,7 +135,7 @@ func TestDataWrite(t *testing.T) {
 		var dl dataList
 		dl.add("arr", []int32{1, 2}, []string{"__aligned__"}, nil)
 		var buf bytes.Buffer
-		if err := dl.write(&buf); err != nil {
+		if err := dl.write(&buf, nil); err != nil {
 			t.Fatal(err)
 		}
 		got := buf.String()
@@ -146,7 +148,7 @@ func TestDataWrite(t *testing.T) {
 		var dl dataList
 		dl.add("name", "hi", nil, new(5))
 		var buf bytes.Buffer
-		if err := dl.write(&buf); err != nil {
+		if err := dl.write(&buf, nil); err != nil {
 			t.Fatal(err)
 		}
 		got := buf.String()
@@ -165,7 +167,7 @@ func TestDataWrite(t *testing.T) {
 		var dl dataList
 		dl.add("name", "hi", nil, new(-5))
 		var buf bytes.Buffer
-		if err := dl.write(&buf); err != nil {
+		if err := dl.write(&buf, nil); err != nil {
 			t.Fatal(err)
 		}
 		got := buf.String()
@@ -181,7 +183,7 @@ func TestDataWrite(t *testing.T) {
 		var dl dataList
 		dl.add("names", []string{"hi", "bye"}, nil, new(5))
 		var buf bytes.Buffer
-		if err := dl.write(&buf); err != nil {
+		if err := dl.write(&buf, nil); err != nil {
 			t.Fatal(err)
 		}
 		got := buf.String()
@@ -200,7 +202,7 @@ func TestDataWrite(t *testing.T) {
 		var dl dataList
 		dl.add("name", "toolong", nil, new(3))
 		var buf bytes.Buffer
-		err := dl.write(&buf)
+		err := dl.write(&buf, nil)
 		if err == nil {
 			t.Fatal("expected width overflow error")
 		}
@@ -214,7 +216,7 @@ func TestDataWrite(t *testing.T) {
 		dl.add("a", int32(1), nil, nil)
 		dl.add("b", int32(2), nil, nil)
 		var buf bytes.Buffer
-		if err := dl.write(&buf); err != nil {
+		if err := dl.write(&buf, nil); err != nil {
 			t.Fatal(err)
 		}
 		got := buf.String()
@@ -224,11 +226,141 @@ func TestDataWrite(t *testing.T) {
 		}
 	})
 
+	t.Run("comments", func(t *testing.T) {
+		var dl dataList
+		dl.add("a", int8(1), nil, nil)
+		dl.add("b", int8(2), nil, nil)
+		dl.comment("a", "first")
+		dl.comment("a", "second")
+		dl.comment("missing", "ignored")
+		var buf bytes.Buffer
+		if err := dl.write(&buf, nil); err != nil {
+			t.Fatal(err)
+		}
+		expected := "\n// first\n// second\nstatic const int8_t a = 0x01;\n\nstatic const int8_t b = 0x02;\n"
+		if buf.String() != expected {
+			t.Errorf("got %q, want %q", buf.String(), expected)
+		}
+	})
+
+	t.Run("padding", func(t *testing.T) {
+		var dl dataList
+		dl.add("a", []int8{1, 2, 3, 1}, nil, nil)
+		dl.add("b", [][]int8{{1, 2, 1, 2}, {3, 4, 3, 4}}, nil, nil)
+		dl.add("c", [][][]int8{{{1, 1}}}, nil, nil)
+		dl.pad("a", 1)
+		dl.pad("b", 2)
+		dl.pad("c", 1)
+		var buf bytes.Buffer
+		if err := dl.write(&buf, nil); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		for _, expected := range []string{
+			"static const int8_t a[4] = {",
+			"#define a_len 3\n#define a_padded_len 4\n",
+			"static const int8_t b[2][4] = {",
+			"#define b_rows 2\n#define b_cols 2\n#define b_padded_cols 4\n",
+			"static const int8_t c[1][1][2] = {",
+			"#define c_len_0 1\n#define c_len_1 1\n#define c_len_2 1\n#define c_padded_len_2 2\n",
+		} {
+			if !strings.Contains(got, expected) {
+				t.Errorf("expected %q in %q", expected, got)
+			}
+		}
+	})
+
+	t.Run("jagged", func(t *testing.T) {
+		var dl dataList
+		dl.add("j", [][]int16{{1, 2, 3}, {4}, {5, 6}}, []string{"PROGMEM"}, nil)
+		var buf bytes.Buffer
+		if err := dl.write(&buf, nil); err != nil {
+			t.Fatal(err)
+		}
+		expected := "\nstatic const int16_t j_data[6] PROGMEM = {\n    0x0001, 0x0002, 0x0003, 0x0004, 0x0005, 0x0006,\n};\n#define j_data_len 6\n" +
+			"\nstatic const uint8_t j_offsets[3] PROGMEM = {\n    0x00, 0x03, 0x04,\n};\n#define j_offsets_len 3\n" +
+			"\nstatic const uint8_t j_lengths[3] PROGMEM = {\n    0x03, 0x01, 0x02,\n};\n#define j_lengths_len 3\n" +
+			"\nstatic const int16_t *const j[3] PROGMEM = {\n    j_data + 0,\n    j_data + 3,\n    j_data + 4,\n};\n#define j_rows 3\n"
+		if buf.String() != expected {
+			t.Errorf("got %q, want %q", buf.String(), expected)
+		}
+	})
+
+	t.Run("jagged_forced_padding", func(t *testing.T) {
+		var dl dataList
+		dl.add("j", renderer.Jagged{[]int8{1, 2, 1}, []int8{3, 4, 3}}, nil, nil)
+		dl.add("k", []any{[]any{"a", "b"}, []any{"c"}}, nil, nil)
+		dl.pad("j", 1)
+		var buf bytes.Buffer
+		if err := dl.write(&buf, nil); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		for _, expected := range []string{
+			"static const int8_t j_data[6] = {",
+			"static const uint8_t j_offsets[2] = {\n    0x00, 0x03,\n};",
+			"static const uint8_t j_lengths[2] = {\n    0x02, 0x02,\n};",
+			"static const int8_t *const j[2] = {",
+			"static const char* k_data[3] = {",
+			"static const char* *const k[2] = {",
+		} {
+			if !strings.Contains(got, expected) {
+				t.Errorf("expected %q in %q", expected, got)
+			}
+		}
+	})
+
+	t.Run("jagged_large_offsets", func(t *testing.T) {
+		var dl dataList
+		dl.add("j", [][]uint8{make([]uint8, 300), {1}}, nil, nil)
+		var buf bytes.Buffer
+		if err := dl.write(&buf, nil); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		for _, expected := range []string{
+			"static const uint16_t j_offsets[2] = {\n    0x0000, 0x012c,\n};",
+			"static const uint16_t j_lengths[2] = {\n    0x012c, 0x0001,\n};",
+		} {
+			if !strings.Contains(got, expected) {
+				t.Errorf("expected %q in %q", expected, got)
+			}
+		}
+	})
+
+	t.Run("error_jagged", func(t *testing.T) {
+		for _, tt := range []struct {
+			name  string
+			value any
+			width *int
+			err   string
+		}{
+			{"mixed_types", []any{[]int8{1}, []int16{1, 2}}, nil, "codegen: jagged data rows must have elements of the same type"},
+			{"3d", [][][]int8{{{1}}, {{1}, {2}}}, nil, "codegen: jagged data must be a slice of slices of scalars or structs"},
+			{"empty", renderer.Jagged{[]int8{}}, nil, "codegen: jagged data is empty"},
+			{"struct_anonymous", [][]struct{ A int8 }{{{1}}, {{1}, {2}}}, nil, "codegen: jagged struct data requires named struct types"},
+			{"str_width", [][]string{{"a"}, {"b", "c"}}, new(3), "codegen: string width is not supported by jagged data"},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				var dl dataList
+				dl.add("bad", tt.value, nil, tt.width)
+				var buf bytes.Buffer
+				err := dl.write(&buf, nil)
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if err.Error() != tt.err {
+					t.Errorf("unexpected error message: %q", err.Error())
+				}
+			})
+		}
+	})
+
 	t.Run("error_nil_value", func(t *testing.T) {
 		var dl dataList
 		dl.add("bad", nil, nil, nil)
 		var buf bytes.Buffer
-		err := dl.write(&buf)
+		err := dl.write(&buf, nil)
 		if err == nil {
 			t.Fatal("expected error for nil value")
 		}
@@ -238,7 +370,7 @@ func TestDataWrite(t *testing.T) {
 		var dl dataList
 		dl.add("bad", complex(1, 2), nil, nil)
 		var buf bytes.Buffer
-		err := dl.write(&buf)
+		err := dl.write(&buf, nil)
 		if err == nil {
 			t.Fatal("expected error for unsupported type")
 		}
@@ -0,0 +1,36 @@
+package codegen
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type function struct {
+	identifier string
+	code       string
+}
+
+type functionList []*function
+
+func (f *functionList) add(identifier string, code string) {
+	*f = append(*f, &function{
+		identifier: identifier,
+		code:       code,
+	})
+}
+
+func (f functionList) write(w io.Writer) error {
+	for _, fn := range f {
+		code := fn.code
+		if !strings.HasSuffix(code, "\n") {
+			code += "\n"
+		}
+
+		if _, err := fmt.Fprintf(w, "\n%s", code); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package codegen
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFunctionWrite(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var fl functionList
+		var buf bytes.Buffer
+		if err := fl.write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != "" {
+			t.Errorf("expected empty output, got %q", buf.String())
+		}
+	})
+
+	t.Run("multiple", func(t *testing.T) {
+		var fl functionList
+		fl.add("foo", "static inline int foo(void) {\n    return 1;\n}\n")
+		fl.add("bar", "static inline int bar(void) {\n    return 2;\n}")
+		var buf bytes.Buffer
+		if err := fl.write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		expected := "\nstatic inline int foo(void) {\n    return 1;\n}\n\nstatic inline int bar(void) {\n    return 2;\n}\n"
+		if buf.String() != expected {
+			t.Errorf("got %q, want %q", buf.String(), expected)
+		}
+	})
+}
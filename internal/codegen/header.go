@@ -1,36 +1,92 @@
 package codegen
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 
+	"rafaelmartins.com/p/synth-datagen/internal/ctypes"
+	"rafaelmartins.com/p/synth-datagen/internal/renderer"
 	"rafaelmartins.com/p/synth-datagen/internal/version"
 )
 
 type Header struct {
-	include includeList
-	macro   macroList
-	data    dataList
+	include  includeList
+	macro    macroList
+	data     dataList
+	function functionList
+	types    structTypeList
 }
 
+var (
+	_ renderer.Renderer         = (*Header)(nil)
+	_ renderer.CommentRenderer  = (*Header)(nil)
+	_ renderer.PaddingRenderer  = (*Header)(nil)
+	_ renderer.FunctionRenderer = (*Header)(nil)
+)
+
 func NewHeader() *Header {
 	return &Header{
-		include: includeList{},
-		macro:   macroList{},
-		data:    dataList{},
+		include:  includeList{},
+		macro:    macroList{},
+		data:     dataList{},
+		function: functionList{},
 	}
 }
 
+// SetStructTypes configures the typedefs declared for the struct data. nameTemplate may use
+// the {name}, {identifier}, {fields} and {index} placeholders, fieldOrder maps typedef names to the
+// struct fields that are moved to the start of the struct, and attributes are added to the
+// typedefs.
+func (h *Header) SetStructTypes(nameTemplate string, fieldOrder map[string][]string, attributes []string) {
+	h.types.nameTemplate = nameTemplate
+	h.types.fieldOrder = fieldOrder
+	h.types.attributes = attributes
+}
+
 func (h *Header) AddInclude(path string, system bool) {
 	h.include.add(path, system)
 }
 
+// typed returns the value of a renderer.Typed value, defining the format macros of values
+// of Q format types.
+func (h *Header) typed(identifier string, value any) any {
+	t, ok := value.(renderer.Typed)
+	if !ok {
+		return value
+	}
+
+	if q, ok := ctypes.ParseQFormat(t.Type); ok {
+		h.macro.add(identifier+"_q_integer_bits", q.IntegerBits, false, false)
+		h.macro.add(identifier+"_q_fractional_bits", q.FractionalBits, false, false)
+	}
+	return t.Value
+}
+
 func (h *Header) AddMacro(identifier string, value any, hex bool, raw bool) {
+	value = h.typed(identifier, value)
 	h.macro.add(identifier, value, hex, raw)
 }
 
 func (h *Header) AddData(identifier string, value any, attributes []string, strWidth *int) {
-	h.data.add(identifier, value, attributes, strWidth)
+	structType := ""
+	if t, ok := value.(renderer.Typed); ok {
+		structType = t.StructType
+	}
+	h.data.add(identifier, h.typed(identifier, value), attributes, strWidth)
+	h.data.nameStruct(identifier, structType)
+}
+
+func (h *Header) AddComment(identifier string, comment string) {
+	h.data.comment(identifier, comment)
+}
+
+func (h *Header) AddPadding(identifier string, padding int) {
+	h.data.pad(identifier, padding)
+}
+
+func (h *Header) AddFunction(identifier string, code string) {
+	h.function.add(identifier, code)
 }
 
 func (h *Header) Write(w io.Writer) error {
@@ -52,7 +108,22 @@ func (h *Header) Write(w io.Writer) error {
 		return err
 	}
 
-	if err := h.data.write(w); err != nil {
+	// the data is written first, to collect the struct types that must be declared before it.
+	h.types.reset()
+	data := bytes.Buffer{}
+	if err := h.data.write(&data, &h.types); err != nil {
+		return err
+	}
+
+	if err := h.types.write(w); err != nil {
+		return err
+	}
+
+	if _, err := data.WriteTo(w); err != nil {
+		return err
+	}
+
+	if err := h.function.write(w); err != nil {
 		return err
 	}
 
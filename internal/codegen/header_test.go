@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"rafaelmartins.com/p/synth-datagen/internal/renderer"
 	"rafaelmartins.com/p/synth-datagen/internal/version"
 )
 
@@ -88,6 +89,20 @@ func TestHeaderWriteDataOnly(t *testing.T) {
 	}
 }
 
+func TestHeaderWriteFunctionsAfterData(t *testing.T) {
+	h := NewHeader()
+	h.AddFunction("get", "static inline int32_t get(void) {\n    return val;\n}\n")
+	h.AddData("val", int32(42), nil, nil)
+	var buf bytes.Buffer
+	if err := h.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := preamble() + "\nstatic const int32_t val = 0x0000002a;\n\nstatic inline int32_t get(void) {\n    return val;\n}\n"
+	if buf.String() != expected {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
+
 func TestHeaderWriteFull(t *testing.T) {
 	h := NewHeader()
 	h.AddInclude("stdint.h", true)
@@ -145,7 +160,7 @@ func TestHeaderWriteDataStruct(t *testing.T) {
 		t.Fatal(err)
 	}
 	got := buf.String()
-	if !strings.Contains(got, "static const struct") {
+	if !strings.Contains(got, "\ntypedef struct {\n    char* name;\n    int32_t value;\n} item_t;\n\nstatic const item_t item = {") {
 		t.Errorf("expected struct type declaration, got %q", got)
 	}
 	if !strings.Contains(got, `"test"`) {
@@ -156,6 +171,34 @@ func TestHeaderWriteDataStruct(t *testing.T) {
 	}
 }
 
+func TestHeaderWriteTyped(t *testing.T) {
+	h := NewHeader()
+	h.AddMacro("gain", renderer.Typed{Type: "q1.14", Value: int16(0x4000)}, false, false)
+	h.AddData("coef", renderer.Typed{Type: "uq16.16", Value: []uint32{0x10000}}, nil, nil)
+	h.AddData("plain", renderer.Typed{Type: "int16_t", Value: int16(1)}, nil, nil)
+	var buf bytes.Buffer
+	if err := h.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := preamble() + `
+#define gain_q_integer_bits 1
+#define gain_q_fractional_bits 14
+#define gain 16384
+#define coef_q_integer_bits 16
+#define coef_q_fractional_bits 16
+
+static const uint32_t coef[1] = {
+    0x00010000,
+};
+#define coef_len 1
+
+static const int16_t plain = 0x0001;
+`
+	if got := buf.String(); got != expected {
+		t.Errorf("got:\n%s\nwant:\n%s", got, expected)
+	}
+}
+
 func TestHeaderWriteDataFloats(t *testing.T) {
 	h := NewHeader()
 	h.AddData("f32", float32(3.14), nil, nil)
@@ -0,0 +1,155 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+
+	"rafaelmartins.com/p/synth-datagen/internal/ctypes"
+	"rafaelmartins.com/p/synth-datagen/internal/renderer"
+)
+
+// jaggedRows returns the rows of a slice of slices, if it is a renderer.Jagged or if the
+// rows don't have the same length.
+func jaggedRows(value any) ([]reflect.Value, bool) {
+	jagged := false
+	if j, ok := value.(renderer.Jagged); ok {
+		value = []any(j)
+		jagged = true
+	}
+
+	val := reflect.ValueOf(value)
+	if val.Kind() != reflect.Slice || val.Len() == 0 {
+		return nil, false
+	}
+
+	rv := make([]reflect.Value, 0, val.Len())
+	for i := 0; i < val.Len(); i++ {
+		row := val.Index(i)
+		if row.Kind() == reflect.Interface {
+			row = row.Elem()
+		}
+		if row.Kind() != reflect.Slice {
+			return nil, false
+		}
+		if i > 0 && row.Len() != rv[0].Len() {
+			jagged = true
+		}
+		rv = append(rv, row)
+	}
+	return rv, jagged
+}
+
+// flattenRows concatenates the rows of a jagged slice into a slice of scalars or structs.
+func flattenRows(rows []reflect.Value) (reflect.Value, error) {
+	var rv reflect.Value
+	for _, row := range rows {
+		for i := 0; i < row.Len(); i++ {
+			v := row.Index(i)
+			if v.Kind() == reflect.Interface {
+				v = v.Elem()
+			}
+			if !rv.IsValid() {
+				if !ctypes.TypeIsScalar(v.Type()) && v.Kind() != reflect.Struct {
+					return rv, errors.New("codegen: jagged data must be a slice of slices of scalars or structs")
+				}
+				rv = reflect.MakeSlice(reflect.SliceOf(v.Type()), 0, 0)
+			}
+			if v.Type() != rv.Type().Elem() {
+				return rv, errors.New("codegen: jagged data rows must have elements of the same type")
+			}
+			rv = reflect.Append(rv, v)
+		}
+	}
+	if !rv.IsValid() {
+		return rv, errors.New("codegen: jagged data is empty")
+	}
+	return rv, nil
+}
+
+// indexSlice returns the values in a slice of the smallest unsigned type that can store
+// values up to max.
+func indexSlice(values []int, max int) any {
+	switch {
+	case max <= math.MaxUint8:
+		rv := make([]uint8, 0, len(values))
+		for _, v := range values {
+			rv = append(rv, uint8(v))
+		}
+		return rv
+
+	case max <= math.MaxUint16:
+		rv := make([]uint16, 0, len(values))
+		for _, v := range values {
+			rv = append(rv, uint16(v))
+		}
+		return rv
+	}
+
+	rv := make([]uint32, 0, len(values))
+	for _, v := range values {
+		rv = append(rv, uint32(v))
+	}
+	return rv
+}
+
+// writeJagged writes a jagged array as a flat backing array with all the rows, arrays with
+// the offset and length of each row, and a table of pointers to the rows. The lengths don't
+// include padding.
+func (dat *data) writeJagged(w io.Writer, rows []reflect.Value, types *structTypeList) error {
+	if dat.strWidth != nil {
+		return errors.New("codegen: string width is not supported by jagged data")
+	}
+
+	flat, err := flattenRows(rows)
+	if err != nil {
+		return err
+	}
+
+	_, ctype, _, err := types.stringify(dat.identifier, dat.structType, flat.Interface())
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(ctype, "struct {") {
+		return errors.New("codegen: jagged struct data requires named struct types")
+	}
+
+	offsets := make([]int, 0, len(rows))
+	lengths := make([]int, 0, len(rows))
+	offset := 0
+	for _, row := range rows {
+		offsets = append(offsets, offset)
+		lengths = append(lengths, max(row.Len()-dat.padding, 0))
+		offset += row.Len()
+	}
+
+	dl := dataList{}
+	dl.add(dat.identifier+"_data", flat.Interface(), dat.attributes, nil)
+	dl.add(dat.identifier+"_offsets", indexSlice(offsets, offset), dat.attributes, nil)
+	dl.add(dat.identifier+"_lengths", indexSlice(lengths, slices.Max(lengths)), dat.attributes, nil)
+	dl[0].typeIdentifier = dat.identifier
+	dl[0].structType = dat.structType
+	dl[0].comments = dat.comments
+	if err := dl.write(w, types); err != nil {
+		return err
+	}
+
+	ptrs := strings.Builder{}
+	ptrs.WriteString("\nstatic const " + ctype + " *const " + dat.identifier + fmt.Sprintf("[%d] ", len(rows)))
+	if len(dat.attributes) > 0 {
+		ptrs.WriteString(strings.Join(dat.attributes, " ") + " ")
+	}
+	ptrs.WriteString("= {\n")
+	for _, o := range offsets {
+		ptrs.WriteString(fmt.Sprintf("    %s_data + %d,\n", dat.identifier, o))
+	}
+	ptrs.WriteString("};\n")
+	ptrs.WriteString(fmt.Sprintf("#define %s_rows %d\n", dat.identifier, len(rows)))
+
+	_, err = io.WriteString(w, ptrs.String())
+	return err
+}
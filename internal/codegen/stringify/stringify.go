@@ -11,6 +11,7 @@ type typeSpec struct {
 	ctype      string
 	stype      []*structSpec
 	dimensions []int
+	fieldOrder []string
 }
 
 func stringify(obj any, level uint8, ts *typeSpec) (string, error) {
@@ -34,8 +35,12 @@ func stringify(obj any, level uint8, ts *typeSpec) (string, error) {
 	}
 }
 
-func Stringify(obj any) (string, string, []int, error) {
-	ts := &typeSpec{}
+// Stringify returns the C initializer, type and dimensions of obj. Struct fields listed in
+// fieldOrder (by their C names) are moved to the start of the struct, in the given order.
+func Stringify(obj any, fieldOrder ...string) (string, string, []int, error) {
+	ts := &typeSpec{
+		fieldOrder: fieldOrder,
+	}
 	data, err := stringify(obj, 0, ts)
 	if err != nil {
 		return "", "", []int{}, err
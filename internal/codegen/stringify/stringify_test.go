@@ -300,6 +300,23 @@ func TestStringify(t *testing.T) {
 	}
 }
 
+func TestStringifyFieldOrder(t *testing.T) {
+	data, ctype, _, err := Stringify([]s3{{Name: "a", Count: 1, Flag: true, Rate: 0.5}}, "rate", "unknown", "flag")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedData := "{\n    {\n        0.5, true, \"a\", 0x00000001,\n    },\n}"
+	if data != expectedData {
+		t.Errorf("expected data: got %q, want %q", data, expectedData)
+	}
+
+	expectedType := "struct {\n    double rate;\n    bool flag;\n    char* name;\n    int32_t count;\n}"
+	if ctype != expectedType {
+		t.Errorf("expected type: got %q, want %q", ctype, expectedType)
+	}
+}
+
 var stringifyValueArgs = []struct {
 	name         string
 	itf          any
@@ -2,6 +2,7 @@ package stringify
 
 import (
 	"reflect"
+	"slices"
 	"strings"
 
 	"rafaelmartins.com/p/synth-datagen/internal/ctypes"
@@ -25,6 +26,29 @@ func stringifyStructType(ts *typeSpec) string {
 	return rv.String() + "}"
 }
 
+// orderFields moves the fields listed in order to the start of the struct, keeping the
+// original order of the remaining fields.
+func orderFields(fields []*structSpec, order []string) []*structSpec {
+	if len(order) == 0 {
+		return fields
+	}
+
+	rv := make([]*structSpec, 0, len(fields))
+	for _, name := range order {
+		for _, field := range fields {
+			if utils.FieldNameToSnake(field.field.Name) == name && !slices.Contains(rv, field) {
+				rv = append(rv, field)
+			}
+		}
+	}
+	for _, field := range fields {
+		if !slices.Contains(rv, field) {
+			rv = append(rv, field)
+		}
+	}
+	return rv
+}
+
 func stringifyStructData(val reflect.Value, level uint8, ts *typeSpec) string {
 	if len(ts.stype) == 0 {
 		for _, field := range reflect.VisibleFields(val.Type()) {
@@ -35,6 +59,7 @@ func stringifyStructData(val reflect.Value, level uint8, ts *typeSpec) string {
 				})
 			}
 		}
+		ts.stype = orderFields(ts.stype, ts.fieldOrder)
 	}
 
 	values := []string{}
@@ -0,0 +1,144 @@
+package codegen
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"rafaelmartins.com/p/synth-datagen/internal/codegen/stringify"
+)
+
+const defaultStructTypeName = "{name}_t"
+
+var reIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type structType struct {
+	name string
+	body string
+}
+
+// structTypeList declares a named typedef for the struct data, instead of anonymous
+// structs. Tables share a typedef when the name template gives them the same name, which
+// is the struct type name set by the module by default.
+type structTypeList struct {
+	nameTemplate string
+	fieldOrder   map[string][]string
+	attributes   []string
+	layouts      []string
+	types        []*structType
+}
+
+// structFields returns the field names of an anonymous struct type, as generated by
+// stringify.
+func structFields(body string) []string {
+	rv := []string{}
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasSuffix(line, ";") {
+			continue
+		}
+		if idx := strings.LastIndex(line, " "); idx >= 0 {
+			rv = append(rv, strings.TrimSuffix(line[idx+1:], ";"))
+		}
+	}
+	return rv
+}
+
+func (s *structTypeList) reset() {
+	s.layouts = nil
+	s.types = nil
+}
+
+// name returns the typedef name of a struct layout used by the data with the given
+// identifier and struct type name. body is the layout with the fields in declaration
+// order, so that the name doesn't depend on the configured field order.
+func (s *structTypeList) name(identifier string, structType string, body string) (string, error) {
+	idx := slices.Index(s.layouts, body)
+	if idx < 0 {
+		idx = len(s.layouts)
+		s.layouts = append(s.layouts, body)
+	}
+
+	tmpl := s.nameTemplate
+	if tmpl == "" {
+		tmpl = defaultStructTypeName
+	}
+	if structType == "" {
+		structType = identifier
+	}
+	rv := strings.NewReplacer(
+		"{name}", structType,
+		"{identifier}", identifier,
+		"{fields}", strings.Join(structFields(body), "_"),
+		"{index}", strconv.Itoa(idx),
+	).Replace(tmpl)
+
+	if !reIdentifier.MatchString(rv) {
+		return "", fmt.Errorf("codegen: invalid struct type name: %q", rv)
+	}
+	return rv, nil
+}
+
+// declare adds a typedef to the list, if it wasn't declared yet. The tables sharing a
+// typedef must have the same layout.
+func (s *structTypeList) declare(name string, body string) error {
+	for _, t := range s.types {
+		if t.name == name {
+			if t.body != body {
+				return fmt.Errorf("codegen: struct type name used by different layouts: %s", name)
+			}
+			return nil
+		}
+	}
+
+	s.types = append(s.types, &structType{
+		name: name,
+		body: body,
+	})
+	return nil
+}
+
+// stringify stringifies the data with the given identifier and struct type name, replacing
+// its anonymous struct type with a typedef name, and ordering the struct fields as
+// configured for the typedef.
+func (s *structTypeList) stringify(identifier string, structType string, value any) (string, string, []int, error) {
+	v, ctype, dim, err := stringify.Stringify(value)
+	if err != nil || s == nil || !strings.HasPrefix(ctype, "struct {") {
+		return v, ctype, dim, err
+	}
+
+	name, err := s.name(identifier, structType, ctype)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if order := s.fieldOrder[name]; len(order) > 0 {
+		v, ctype, dim, err = stringify.Stringify(value, order...)
+		if err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	if err := s.declare(name, ctype); err != nil {
+		return "", "", nil, err
+	}
+	return v, name, dim, nil
+}
+
+func (s *structTypeList) write(w io.Writer) error {
+	attrs := ""
+	if len(s.attributes) > 0 {
+		attrs = strings.Join(s.attributes, " ") + " "
+	}
+
+	for _, t := range s.types {
+		body := strings.Replace(t.body, "struct {", "struct "+attrs+"{", 1)
+		if _, err := fmt.Fprintf(w, "\ntypedef %s %s;\n", body, t.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package codegen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"rafaelmartins.com/p/synth-datagen/internal/renderer"
+)
+
+type coefficients struct {
+	B0 int16
+	B1 int16
+	A1 int16
+}
+
+func TestStructTypes(t *testing.T) {
+	t.Run("struct_type", func(t *testing.T) {
+		for _, order := range [][]string{{"lowpass", "highpass"}, {"highpass", "lowpass"}} {
+			h := NewHeader()
+			h.SetStructTypes("", map[string][]string{"coef_t": {"a1"}}, nil)
+			for _, id := range order {
+				h.AddData(id, renderer.Typed{Type: "int16_t", StructType: "coef", Value: []coefficients{{1, 2, 3}}}, nil, nil)
+			}
+			var buf bytes.Buffer
+			if err := h.Write(&buf); err != nil {
+				t.Fatal(err)
+			}
+			got := buf.String()
+			for _, expected := range []string{
+				"\ntypedef struct {\n    int16_t a1;\n    int16_t b0;\n    int16_t b1;\n} coef_t;\n",
+				"\nstatic const coef_t lowpass[1] = {\n    {\n        0x0003, 0x0001, 0x0002,\n    },\n};\n",
+				"\nstatic const coef_t highpass[1] = {",
+			} {
+				if !strings.Contains(got, expected) {
+					t.Errorf("%v: expected %q in %q", order, expected, got)
+				}
+			}
+			if strings.Count(got, "typedef") != 1 {
+				t.Errorf("%v: expected a single typedef, got %q", order, got)
+			}
+		}
+	})
+
+	t.Run("identifier", func(t *testing.T) {
+		for _, order := range [][]string{{"lowpass", "highpass"}, {"highpass", "lowpass"}} {
+			h := NewHeader()
+			for _, id := range order {
+				h.AddData(id, []coefficients{{1, 2, 3}}, nil, nil)
+			}
+			var buf bytes.Buffer
+			if err := h.Write(&buf); err != nil {
+				t.Fatal(err)
+			}
+			got := buf.String()
+			for _, expected := range []string{
+				"\ntypedef struct {\n    int16_t b0;\n    int16_t b1;\n    int16_t a1;\n} lowpass_t;\n",
+				"\ntypedef struct {\n    int16_t b0;\n    int16_t b1;\n    int16_t a1;\n} highpass_t;\n",
+				"\nstatic const lowpass_t lowpass[1] = {",
+				"\nstatic const highpass_t highpass[1] = {",
+			} {
+				if !strings.Contains(got, expected) {
+					t.Errorf("%v: expected %q in %q", order, expected, got)
+				}
+			}
+		}
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		h := NewHeader()
+		h.SetStructTypes("{fields}_t", nil, nil)
+		h.AddData("lowpass", []coefficients{{1, 2, 3}}, nil, nil)
+		h.AddData("highpass", []coefficients{{4, 5, 6}}, nil, nil)
+		var buf bytes.Buffer
+		if err := h.Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		for _, expected := range []string{
+			"\ntypedef struct {\n    int16_t b0;\n    int16_t b1;\n    int16_t a1;\n} b0_b1_a1_t;\n",
+			"\nstatic const b0_b1_a1_t lowpass[1] = {",
+			"\nstatic const b0_b1_a1_t highpass[1] = {",
+		} {
+			if !strings.Contains(got, expected) {
+				t.Errorf("expected %q in %q", expected, got)
+			}
+		}
+		if strings.Count(got, "typedef") != 1 {
+			t.Errorf("expected a single typedef, got %q", got)
+		}
+	})
+
+	t.Run("distinct_layouts", func(t *testing.T) {
+		h := NewHeader()
+		h.SetStructTypes("coef{index}_t", nil, nil)
+		h.AddData("a", coefficients{1, 2, 3}, nil, nil)
+		h.AddData("b", struct{ G float32 }{1}, nil, nil)
+		h.AddData("c", coefficients{4, 5, 6}, nil, nil)
+		var buf bytes.Buffer
+		if err := h.Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		for _, expected := range []string{
+			"} coef0_t;\n",
+			"\ntypedef struct {\n    float g;\n} coef1_t;\n",
+			"static const coef0_t a = {",
+			"static const coef1_t b = {",
+			"static const coef0_t c = {",
+		} {
+			if !strings.Contains(got, expected) {
+				t.Errorf("expected %q in %q", expected, got)
+			}
+		}
+	})
+
+	t.Run("fields_order_attributes", func(t *testing.T) {
+		h := NewHeader()
+		h.SetStructTypes("filter_{fields}_t", map[string][]string{
+			"filter_b0_b1_a1_t": {"a1", "b1"},
+		}, []string{"__attribute__((packed))"})
+		h.AddData("f", coefficients{1, 2, 3}, nil, nil)
+		h.AddData("g", struct{ B0, G float32 }{1, 2}, nil, nil)
+		var buf bytes.Buffer
+		if err := h.Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		for _, expected := range []string{
+			"\ntypedef struct __attribute__((packed)) {\n    int16_t a1;\n    int16_t b1;\n    int16_t b0;\n} filter_b0_b1_a1_t;\n",
+			"\ntypedef struct __attribute__((packed)) {\n    float b0;\n    float g;\n} filter_b0_g_t;\n",
+			"static const filter_b0_b1_a1_t f = {\n    0x0003, 0x0002, 0x0001,\n};\n",
+			"static const filter_b0_g_t g = {",
+		} {
+			if !strings.Contains(got, expected) {
+				t.Errorf("expected %q in %q", expected, got)
+			}
+		}
+	})
+
+	t.Run("jagged", func(t *testing.T) {
+		h := NewHeader()
+		h.AddData("j", renderer.Jagged{[]coefficients{{1, 2, 3}}, []coefficients{{4, 5, 6}, {7, 8, 9}}}, nil, nil)
+		var buf bytes.Buffer
+		if err := h.Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		for _, expected := range []string{
+			"} j_t;\n",
+			"static const j_t j_data[3] = {",
+			"static const j_t *const j[2] = {",
+		} {
+			if !strings.Contains(got, expected) {
+				t.Errorf("expected %q in %q", expected, got)
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, tt := range []struct {
+			name string
+			tmpl string
+			err  string
+		}{
+			{"invalid_name", "{identifier} t", `codegen: invalid struct type name: "a t"`},
+			{"conflict", "coef_t", "codegen: struct type name used by different layouts: coef_t"},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				h := NewHeader()
+				h.SetStructTypes(tt.tmpl, nil, nil)
+				h.AddData("a", coefficients{1, 2, 3}, nil, nil)
+				h.AddData("b", struct{ G float32 }{1}, nil, nil)
+				var buf bytes.Buffer
+				err := h.Write(&buf)
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if err.Error() != tt.err {
+					t.Errorf("unexpected error message: %q", err.Error())
+				}
+			})
+		}
+	})
+}
@@ -1,9 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
 
 	"go.yaml.in/yaml/v3"
+	"rafaelmartins.com/p/synth-datagen/internal/convert"
 )
 
 type Config struct {
@@ -11,6 +13,19 @@ type Config struct {
 	Outputs          Outputs        `yaml:"output"`
 }
 
+func globalString(params map[string]any, key string) (*string, error) {
+	v, ok := params[key]
+	if !ok {
+		return nil, nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("config: global_parameters: %s: not a string", key)
+	}
+	return &s, nil
+}
+
 func New(file string) (*Config, error) {
 	f, err := os.Open(file)
 	if err != nil {
@@ -18,8 +33,34 @@ func New(file string) (*Config, error) {
 	}
 	defer f.Close()
 
+	node := yaml.Node{}
+	if err := yaml.NewDecoder(f).Decode(&node); err != nil {
+		return nil, err
+	}
+
+	// global parameters are decoded first, because they define the default conversion
+	// options for macros and variables.
+	glb := struct {
+		GlobalParameters map[string]any `yaml:"global_parameters"`
+	}{}
+	if err := node.Decode(&glb); err != nil {
+		return nil, err
+	}
+
+	rounding, err := globalString(glb.GlobalParameters, "rounding")
+	if err != nil {
+		return nil, err
+	}
+	overflow, err := globalString(glb.GlobalParameters, "overflow")
+	if err != nil {
+		return nil, err
+	}
+	if err := convert.SetDefaultOptions(rounding, overflow); err != nil {
+		return nil, err
+	}
+
 	rv := &Config{}
-	if err := yaml.NewDecoder(f).Decode(rv); err != nil {
+	if err := node.Decode(rv); err != nil {
 		return nil, err
 	}
 
@@ -18,6 +18,8 @@ type Macro struct {
 	Raw        bool           `yaml:"raw"`
 	Eval       bool           `yaml:"eval"`
 	EvalEnv    map[string]any `yaml:"eval_env"`
+	Rounding   *string        `yaml:"rounding"`
+	Overflow   *string        `yaml:"overflow"`
 }
 
 type Macros []*Macro
@@ -70,7 +72,12 @@ func (c *Macros) UnmarshalYAML(value *yaml.Node) error {
 				}
 
 				if m.Type != "" {
-					value, err := convert.Scalar(m.Value, m.Type)
+					opts, err := convert.NewOptions(m.Rounding, m.Overflow)
+					if err != nil {
+						return err
+					}
+
+					value, err := convert.Scalar(m.Value, m.Type, opts.WithIdentifier(m.Identifier))
 					if err != nil {
 						return err
 					}
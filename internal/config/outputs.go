@@ -6,13 +6,20 @@ import (
 	"go.yaml.in/yaml/v3"
 )
 
+type StructTypes struct {
+	NameTemplate string              `yaml:"name_template"`
+	FieldOrder   map[string][]string `yaml:"field_order"`
+	Attributes   []string            `yaml:"attributes"`
+}
+
 type Output struct {
-	HeaderOutput string    `yaml:"-"`
-	ChartsOutput string    `yaml:"charts_output"`
-	Includes     Includes  `yaml:"includes"`
-	Macros       Macros    `yaml:"macros"`
-	Variables    Variables `yaml:"variables"`
-	Modules      Modules   `yaml:"modules"`
+	HeaderOutput string       `yaml:"-"`
+	ChartsOutput string       `yaml:"charts_output"`
+	Includes     Includes     `yaml:"includes"`
+	Macros       Macros       `yaml:"macros"`
+	Variables    Variables    `yaml:"variables"`
+	Modules      Modules      `yaml:"modules"`
+	StructTypes  *StructTypes `yaml:"struct_types"`
 }
 
 type Outputs []*Output
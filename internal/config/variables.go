@@ -18,6 +18,8 @@ type Variable struct {
 	Attributes  []string       `yaml:"attributes"`
 	Eval        bool           `yaml:"eval"`
 	EvalEnv     map[string]any `yaml:"eval_env"`
+	Rounding    *string        `yaml:"rounding"`
+	Overflow    *string        `yaml:"overflow"`
 }
 
 type Variables []*Variable
@@ -66,15 +68,20 @@ func (c *Variables) UnmarshalYAML(value *yaml.Node) error {
 				}
 
 				if m.Type != "" {
+					opts, err := convert.NewOptions(m.Rounding, m.Overflow)
+					if err != nil {
+						return err
+					}
+
 					v := reflect.ValueOf(m.Value)
 					if v.Kind() == reflect.Slice {
-						value, err := convert.Slice(m.Value, m.Type)
+						value, err := convert.Slice(m.Value, m.Type, opts.WithIdentifier(m.Identifier))
 						if err != nil {
 							return err
 						}
 						m.Value = value
 					} else if ctypes.TypeIsScalar(v.Type()) {
-						value, err := convert.Scalar(m.Value, m.Type)
+						value, err := convert.Scalar(m.Value, m.Type, opts.WithIdentifier(m.Identifier))
 						if err != nil {
 							return err
 						}
@@ -0,0 +1,346 @@
+package convert
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"rafaelmartins.com/p/synth-datagen/internal/ctypes"
+)
+
+type Rounding int
+
+const (
+	RoundingTruncate Rounding = iota
+	RoundingNearest
+	RoundingFloor
+)
+
+type Overflow int
+
+const (
+	OverflowWrap Overflow = iota
+	OverflowSaturate
+	OverflowError
+)
+
+// Quantizer replaces the regular rounding of 1-D tables of floating point values converted
+// to integer types. It must return integral values in the [min, max] range of the type.
+type Quantizer interface {
+	Quantize(values []float64, min float64, max float64) []float64
+}
+
+type Options struct {
+	Identifier string
+	Rounding   Rounding
+	Overflow   Overflow
+	Quantizer  Quantizer
+
+	// floating point values are multiplied by 2^FractionalBitWidth before conversion.
+	FractionalBitWidth int
+
+	qformat *ctypes.QFormat
+}
+
+var defaultOptions = Options{
+	Rounding: RoundingTruncate,
+	Overflow: OverflowWrap,
+}
+
+func parseOptions(opts *Options, rounding *string, overflow *string) error {
+	if rounding != nil {
+		switch *rounding {
+		case "truncate":
+			opts.Rounding = RoundingTruncate
+		case "nearest":
+			opts.Rounding = RoundingNearest
+		case "floor":
+			opts.Rounding = RoundingFloor
+		default:
+			return fmt.Errorf("convert: invalid rounding mode: %s", *rounding)
+		}
+	}
+
+	if overflow != nil {
+		switch *overflow {
+		case "wrap":
+			opts.Overflow = OverflowWrap
+		case "saturate":
+			opts.Overflow = OverflowSaturate
+		case "error":
+			opts.Overflow = OverflowError
+		default:
+			return fmt.Errorf("convert: invalid overflow policy: %s", *overflow)
+		}
+	}
+
+	return nil
+}
+
+func SetDefaultOptions(rounding *string, overflow *string) error {
+	opts := Options{
+		Rounding: RoundingTruncate,
+		Overflow: OverflowWrap,
+	}
+	if err := parseOptions(&opts, rounding, overflow); err != nil {
+		return err
+	}
+	defaultOptions = opts
+	return nil
+}
+
+func NewOptions(rounding *string, overflow *string) (*Options, error) {
+	rv := defaultOptions
+	if err := parseOptions(&rv, rounding, overflow); err != nil {
+		return nil, err
+	}
+	return &rv, nil
+}
+
+func (o *Options) WithIdentifier(identifier string) *Options {
+	rv := defaultOptions
+	if o != nil {
+		rv = *o
+	}
+	rv.Identifier = identifier
+	return &rv
+}
+
+func (o *Options) WithFractionalBitWidth(bw *uint8) *Options {
+	rv := defaultOptions
+	if o != nil {
+		rv = *o
+	}
+	rv.FractionalBitWidth = 0
+	if bw != nil {
+		rv.FractionalBitWidth = int(*bw)
+	}
+	return &rv
+}
+
+// forType returns the options to convert values to the given type. Q format types define
+// the fractional bit width themselves, and saturate unless the overflow policy is error.
+func (o *Options) forType(to string) (*Options, error) {
+	if o == nil {
+		o = &defaultOptions
+	}
+
+	q, ok := ctypes.ParseQFormat(to)
+	if !ok || o.qformat != nil {
+		return o, nil
+	}
+
+	if o.FractionalBitWidth != 0 {
+		return nil, fmt.Errorf("%sfractional bit width can't be used with Q format type: %s", o.location(""), to)
+	}
+
+	rv := *o
+	rv.FractionalBitWidth = q.FractionalBits
+	rv.qformat = q
+	if rv.Overflow == OverflowWrap {
+		rv.Overflow = OverflowSaturate
+	}
+	return &rv, nil
+}
+
+func (o *Options) scale() float64 {
+	if o == nil {
+		o = &defaultOptions
+	}
+	return math.Ldexp(1, o.FractionalBitWidth)
+}
+
+// Scale returns the factor that values converted to the given type are multiplied by.
+func Scale(to string, opts *Options) (float64, error) {
+	o, err := opts.forType(to)
+	if err != nil {
+		return 0, err
+	}
+	return o.scale(), nil
+}
+
+// Limits returns the range of values that can be converted to the given type without
+// overflowing, before scaling. Types without limits (e.g. double) return infinite limits.
+func Limits(to string, opts *Options) (float64, float64, error) {
+	typ, err := ctypes.ToType(to)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	o, err := opts.forType(to)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	min, max, ok := limits(typ, o.qformat)
+	if !ok {
+		return math.Inf(-1), math.Inf(1), nil
+	}
+	return min / o.scale(), max / o.scale(), nil
+}
+
+func (o *Options) round(f float64) float64 {
+	if o == nil {
+		o = &defaultOptions
+	}
+
+	switch o.Rounding {
+	case RoundingNearest:
+		return math.Round(f)
+	case RoundingFloor:
+		return math.Floor(f)
+	}
+	return math.Trunc(f)
+}
+
+func (o *Options) location(index string) string {
+	if o == nil || o.Identifier == "" {
+		if index == "" {
+			return ""
+		}
+		return index + ": "
+	}
+	return o.Identifier + index + ": "
+}
+
+func isInteger(k reflect.Kind) bool {
+	return isSigned(k) || isUnsigned(k)
+}
+
+func isSigned(k reflect.Kind) bool {
+	return k == reflect.Int || k == reflect.Int8 || k == reflect.Int16 || k == reflect.Int32 || k == reflect.Int64
+}
+
+func isUnsigned(k reflect.Kind) bool {
+	return k == reflect.Uint || k == reflect.Uint8 || k == reflect.Uint16 || k == reflect.Uint32 || k == reflect.Uint64 || k == reflect.Uintptr
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func limits(typ reflect.Type, q *ctypes.QFormat) (float64, float64, bool) {
+	if q != nil {
+		if q.Signed {
+			return -math.Ldexp(1, q.Bits()-1), math.Ldexp(1, q.Bits()-1) - 1, true
+		}
+		return 0, math.Ldexp(1, q.Bits()) - 1, true
+	}
+
+	bits := typ.Bits()
+	switch {
+	case isSigned(typ.Kind()):
+		return -math.Ldexp(1, bits-1), math.Ldexp(1, bits-1) - 1, true
+	case isUnsigned(typ.Kind()):
+		return 0, math.Ldexp(1, bits) - 1, true
+	case typ.Kind() == reflect.Float32:
+		return -math.MaxFloat32, math.MaxFloat32, true
+	}
+	return 0, 0, false
+}
+
+// intLimits returns the exact limits of an integer type, and its number of bits.
+func intLimits(typ reflect.Type, q *ctypes.QFormat) (int64, uint64, int) {
+	bits, signed := typ.Bits(), isSigned(typ.Kind())
+	if q != nil {
+		bits, signed = q.Bits(), q.Signed
+	}
+	if signed {
+		return -1 << (bits - 1), 1<<(bits-1) - 1, bits
+	}
+	return 0, ^uint64(0) >> (64 - bits), bits
+}
+
+// wrap converts floating point values to integers through int64, so that out of range
+// values wrap around consistently across architectures.
+func wrap(val reflect.Value, typ reflect.Type) reflect.Value {
+	if isFloat(val.Kind()) && isInteger(typ.Kind()) {
+		if f := val.Float(); f >= math.MinInt64 && f < math.MaxInt64 {
+			return reflect.ValueOf(int64(f)).Convert(typ)
+		}
+	}
+	return val.Convert(typ)
+}
+
+// value converts a scalar value to the given type, applying the rounding mode and the
+// overflow policy.
+func value(val reflect.Value, typ reflect.Type, opts *Options, index string) (reflect.Value, error) {
+	if opts == nil {
+		opts = &defaultOptions
+	}
+
+	if isFloat(val.Kind()) && opts.FractionalBitWidth != 0 {
+		val = reflect.ValueOf(val.Float() * opts.scale())
+	}
+
+	if opts.Rounding == RoundingTruncate && opts.Overflow == OverflowWrap {
+		return wrap(val, typ), nil
+	}
+
+	if !(isInteger(val.Kind()) || isFloat(val.Kind())) || !(isInteger(typ.Kind()) || isFloat(typ.Kind())) {
+		return val.Convert(typ), nil
+	}
+
+	if isFloat(val.Kind()) && isInteger(typ.Kind()) {
+		val = reflect.ValueOf(opts.round(val.Float()))
+	}
+
+	mn, mx, ok := limits(typ, opts.qformat)
+	if !ok || opts.Overflow == OverflowWrap {
+		return wrap(val, typ), nil
+	}
+
+	overflow := false
+	below := false
+	if isInteger(typ.Kind()) {
+		// integer limits are compared as integers, and as exact powers of two in float
+		// space, because 64-bit limits can't be represented as float64.
+		imn, imx, bits := intLimits(typ, opts.qformat)
+		switch {
+		case isSigned(val.Kind()):
+			v := val.Int()
+			overflow, below = v < imn || (v > 0 && uint64(v) > imx), v < imn
+
+		case isUnsigned(val.Kind()):
+			overflow = val.Uint() > imx
+
+		default:
+			v := val.Float()
+			if math.IsNaN(v) {
+				return reflect.Value{}, fmt.Errorf("%svalue %v can't be represented as %s", opts.location(index), val.Interface(), typ)
+			}
+			hi := math.Ldexp(1, bits)
+			if imn < 0 {
+				hi = math.Ldexp(1, bits-1)
+			}
+			overflow, below = v < float64(imn) || v >= hi, v < float64(imn)
+		}
+
+		if overflow && opts.Overflow == OverflowSaturate {
+			if below {
+				return reflect.ValueOf(imn).Convert(typ), nil
+			}
+			return reflect.ValueOf(imx).Convert(typ), nil
+		}
+	} else if isFloat(val.Kind()) {
+		v := val.Float()
+		overflow, below = v < mn || v > mx, v < mn
+	}
+
+	if !overflow {
+		return val.Convert(typ), nil
+	}
+
+	if opts.Overflow == OverflowError {
+		name := typ.String()
+		if opts.qformat != nil {
+			name = opts.qformat.Name
+		}
+		return reflect.Value{}, fmt.Errorf("%svalue %v overflows %s", opts.location(index), val.Interface(), name)
+	}
+
+	if below {
+		return reflect.ValueOf(mn).Convert(typ), nil
+	}
+	return reflect.ValueOf(mx).Convert(typ), nil
+}
@@ -0,0 +1,332 @@
+package convert
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestNewOptions(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		opts, err := NewOptions(nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if opts.Rounding != RoundingTruncate || opts.Overflow != OverflowWrap {
+			t.Errorf("unexpected options: %+v", opts)
+		}
+	})
+
+	t.Run("invalid_rounding", func(t *testing.T) {
+		_, err := NewOptions(ptr("ceil"), nil)
+		if err == nil {
+			t.Fatal("expected error for invalid rounding mode")
+		}
+		if err.Error() != "convert: invalid rounding mode: ceil" {
+			t.Errorf("unexpected error message: %q", err.Error())
+		}
+	})
+
+	t.Run("invalid_overflow", func(t *testing.T) {
+		_, err := NewOptions(nil, ptr("clip"))
+		if err == nil {
+			t.Fatal("expected error for invalid overflow policy")
+		}
+		if err.Error() != "convert: invalid overflow policy: clip" {
+			t.Errorf("unexpected error message: %q", err.Error())
+		}
+	})
+}
+
+func TestSliceOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		rounding string
+		overflow string
+		input    []float64
+		expected []int8
+	}{
+		{"truncate_wrap", "truncate", "wrap", []float64{1.7, -1.7, 128, -129}, []int8{1, -1, -128, 127}},
+		{"nearest_wrap", "nearest", "wrap", []float64{1.5, -1.5, 2.4, -2.4}, []int8{2, -2, 2, -2}},
+		{"floor_wrap", "floor", "wrap", []float64{1.7, -1.2}, []int8{1, -2}},
+		{"truncate_saturate", "truncate", "saturate", []float64{127.9, 200, -200, -128.5}, []int8{127, 127, -128, -128}},
+		{"nearest_saturate", "nearest", "saturate", []float64{127.5, -128.5, 12.5}, []int8{127, -128, 13}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := NewOptions(ptr(tt.rounding), ptr(tt.overflow))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := Slice(tt.input, "int8_t", opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+
+	t.Run("saturate_unsigned", func(t *testing.T) {
+		opts, err := NewOptions(nil, ptr("saturate"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := Slice([]int{-1, 256, 10}, "uint8_t", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result, []uint8{0, 255, 10}) {
+			t.Errorf("unexpected result: %v", result)
+		}
+	})
+
+	t.Run("error_nested", func(t *testing.T) {
+		opts, err := NewOptions(nil, ptr("error"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Slice([][]float64{{1, 2}, {3, 40000}}, "int16_t", opts.WithIdentifier("table"))
+		if err == nil {
+			t.Fatal("expected overflow error")
+		}
+		if err.Error() != "slice: table[1][1]: value 40000 overflows int16" {
+			t.Errorf("unexpected error message: %q", err.Error())
+		}
+	})
+}
+
+func TestSliceStructOptions(t *testing.T) {
+	type coef struct {
+		A float64
+		B float64
+	}
+
+	opts, err := NewOptions(ptr("nearest"), ptr("error"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = SliceStruct([]coef{{1, 2}, {3, -200}}, "int8_t", opts.WithIdentifier("coefs"))
+	if err == nil {
+		t.Fatal("expected overflow error")
+	}
+	if err.Error() != "slicestruct: coefs[1].B: value -200 overflows int8" {
+		t.Errorf("unexpected error message: %q", err.Error())
+	}
+}
+
+func TestScalarOptions(t *testing.T) {
+	opts, err := NewOptions(ptr("nearest"), ptr("saturate"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Scalar(65535.6, "uint16_t", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != uint16(65535) {
+		t.Errorf("unexpected result: %v", result)
+	}
+
+	result, err = Scalar(2.5, "int32_t", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != int32(3) {
+		t.Errorf("unexpected result: %v", result)
+	}
+}
+
+func TestScalarOptions64(t *testing.T) {
+	tests := []struct {
+		name     string
+		overflow string
+		input    any
+		to       string
+		expected any
+		err      string
+	}{
+		{"int64_saturate_small", "saturate", 5, "int64_t", int64(5), ""},
+		{"int64_saturate_neg", "saturate", -5, "int64_t", int64(-5), ""},
+		{"int64_saturate_float", "saturate", 5.0, "int64_t", int64(5), ""},
+		{"int64_saturate_max", "saturate", int64(math.MaxInt64), "int64_t", int64(math.MaxInt64), ""},
+		{"int64_saturate_min", "saturate", int64(math.MinInt64), "int64_t", int64(math.MinInt64), ""},
+		{"int64_saturate_big", "saturate", 1e30, "int64_t", int64(math.MaxInt64), ""},
+		{"int64_saturate_small_float", "saturate", -1e30, "int64_t", int64(math.MinInt64), ""},
+		{"int64_saturate_2_63", "saturate", math.Ldexp(1, 63), "int64_t", int64(math.MaxInt64), ""},
+		{"int64_saturate_uint", "saturate", uint64(math.MaxUint64), "int64_t", int64(math.MaxInt64), ""},
+		{"int64_error_small", "error", 5, "int64_t", int64(5), ""},
+		{"int64_error_big", "error", 1e30, "int64_t", nil, "scalar: value 1e+30 overflows int64"},
+		{"uint64_saturate_small", "saturate", 5, "uint64_t", uint64(5), ""},
+		{"uint64_saturate_max", "saturate", uint64(math.MaxUint64), "uint64_t", uint64(math.MaxUint64), ""},
+		{"uint64_saturate_big", "saturate", 1e30, "uint64_t", uint64(math.MaxUint64), ""},
+		{"uint64_saturate_2_64", "saturate", math.Ldexp(1, 64), "uint64_t", uint64(math.MaxUint64), ""},
+		{"uint64_saturate_neg", "saturate", -5, "uint64_t", uint64(0), ""},
+		{"uint64_saturate_neg_float", "saturate", -1e30, "uint64_t", uint64(0), ""},
+		{"uint64_error_small", "error", 5, "uint64_t", uint64(5), ""},
+		{"uint64_error_neg", "error", -5, "uint64_t", nil, "scalar: value -5 overflows uint64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := NewOptions(ptr("nearest"), ptr(tt.overflow))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := Scalar(tt.input, tt.to, opts)
+			if tt.err != "" {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if err.Error() != tt.err {
+					t.Errorf("unexpected error message: %q", err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+type offsetQuantizer float64
+
+func (q offsetQuantizer) Quantize(values []float64, min float64, max float64) []float64 {
+	for i := range values {
+		values[i] = math.Min(math.Max(math.Floor(values[i]+float64(q)), min), max)
+	}
+	return values
+}
+
+func TestSliceQuantizer(t *testing.T) {
+	opts, err := NewOptions(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Quantizer = offsetQuantizer(0.5)
+
+	input := []float64{1.4, 1.5, 127.6, -128.6}
+	result, err := Slice(input, "int8_t", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, []int8{1, 2, 127, -128}) {
+		t.Errorf("unexpected result: %v", result)
+	}
+	if !reflect.DeepEqual(input, []float64{1.4, 1.5, 127.6, -128.6}) {
+		t.Errorf("input modified: %v", input)
+	}
+
+	result, err = Slice(input, "float", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, []float32{1.4, 1.5, 127.6, -128.6}) {
+		t.Errorf("quantizer used for floating point type: %v", result)
+	}
+}
+
+func TestSliceQFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		to       string
+		input    []float64
+		expected any
+	}{
+		{"q15", "q15", []float64{0.5, -1, 1, -0.25}, []int16{0x4000, -0x8000, 0x7fff, -0x2000}},
+		{"q7", "q7", []float64{0.5, 2}, []int8{0x40, 0x7f}},
+		{"q1.14", "q1.14", []float64{1.5, -2.5}, []int16{0x6000, -0x8000}},
+		{"q3.4", "q3.4", []float64{1.5, 9}, []int8{0x18, 0x7f}},
+		{"uq16.16", "uq16.16", []float64{1.5, -1}, []uint32{0x00018000, 0}},
+		{"uq4.4", "uq4.4", []float64{1.5, 16}, []uint8{0x18, 0xff}},
+		{"q11_storage", "q11", []float64{1, -1}, []int16{0x7ff, -0x800}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Slice(tt.input, tt.to, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+
+	t.Run("fractional_bit_width", func(t *testing.T) {
+		bw := uint8(4)
+		_, err := Slice([]float64{1}, "q15", (&Options{}).WithFractionalBitWidth(&bw))
+		if err == nil {
+			t.Fatal("expected error for fractional bit width with Q format")
+		}
+		if err.Error() != "slice: fractional bit width can't be used with Q format type: q15" {
+			t.Errorf("unexpected error message: %q", err.Error())
+		}
+	})
+
+	t.Run("overflow_error", func(t *testing.T) {
+		opts, err := NewOptions(nil, ptr("error"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Slice([]float64{0.5, 1}, "q15", opts.WithIdentifier("gain"))
+		if err == nil {
+			t.Fatal("expected overflow error")
+		}
+		if err.Error() != "slice: gain[1]: value 32768 overflows q15" {
+			t.Errorf("unexpected error message: %q", err.Error())
+		}
+	})
+
+	t.Run("scale", func(t *testing.T) {
+		s, err := Scale("q1.14", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != 16384 {
+			t.Errorf("unexpected scale: %f", s)
+		}
+	})
+}
+
+func TestLimits(t *testing.T) {
+	bw := uint8(4)
+	tests := []struct {
+		name string
+		to   string
+		opts *Options
+		min  float64
+		max  float64
+	}{
+		{"uint8", "uint8_t", nil, 0, 255},
+		{"int16", "int16_t", nil, -32768, 32767},
+		{"int16_fractional", "int16_t", (&Options{}).WithFractionalBitWidth(&bw), -2048, 32767. / 16},
+		{"q15", "q15", nil, -1, 32767. / 32768},
+		{"uq4.4", "uq4.4", nil, 0, 255. / 16},
+		{"double", "double", nil, math.Inf(-1), math.Inf(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			min, max, err := Limits(tt.to, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if min != tt.min || max != tt.max {
+				t.Errorf("expected [%g, %g], got [%g, %g]", tt.min, tt.max, min, max)
+			}
+		})
+	}
+}
@@ -8,7 +8,7 @@ import (
 	"rafaelmartins.com/p/synth-datagen/internal/ctypes"
 )
 
-func Scalar(scalar any, to string) (any, error) {
+func Scalar(scalar any, to string, opts *Options) (any, error) {
 	if scalar == nil {
 		return nil, errors.New("scalar: got nil")
 	}
@@ -25,8 +25,17 @@ func Scalar(scalar any, to string) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	opts, err = opts.forType(to)
+	if err != nil {
+		return nil, fmt.Errorf("scalar: %w", err)
+	}
+
 	if !val.CanConvert(typ) {
 		return nil, fmt.Errorf("slice: value of type %s cannot be converted to type %s", val.Type(), typ)
 	}
-	return val.Convert(typ).Interface(), nil
+	rv, err := value(val, typ, opts, "")
+	if err != nil {
+		return nil, fmt.Errorf("scalar: %w", err)
+	}
+	return rv.Interface(), nil
 }
@@ -8,7 +8,11 @@ import (
 	"rafaelmartins.com/p/synth-datagen/internal/ctypes"
 )
 
-func Slice(slice any, to string) (any, error) {
+func Slice(slice any, to string, opts *Options) (any, error) {
+	return convertSlice(slice, to, opts, "")
+}
+
+func convertSlice(slice any, to string, opts *Options, index string) (any, error) {
 	if slice == nil {
 		return nil, errors.New("slice: got nil")
 	}
@@ -39,6 +43,24 @@ func Slice(slice any, to string) (any, error) {
 		if err != nil {
 			return nil, err
 		}
+
+		opts, err = opts.forType(to)
+		if err != nil {
+			return nil, fmt.Errorf("slice: %w", err)
+		}
+	}
+
+	if f, ok := slice.([]float64); ok && opts != nil && opts.Quantizer != nil && isInteger(typ.Kind()) {
+		values := make([]float64, 0, len(f))
+		for _, v := range f {
+			values = append(values, v*opts.scale())
+		}
+		mn, mx, _ := limits(typ, opts.qformat)
+		val = reflect.ValueOf(opts.Quantizer.Quantize(values, mn, mx))
+
+		o := *opts
+		o.FractionalBitWidth = 0
+		opts = &o
 	}
 
 	rv := reflect.Value{}
@@ -48,7 +70,7 @@ func Slice(slice any, to string) (any, error) {
 			eval = reflect.ValueOf(eval.Interface())
 		}
 		if eval.Kind() == reflect.Slice {
-			r, err := Slice(eval.Interface(), to)
+			r, err := convertSlice(eval.Interface(), to, opts, fmt.Sprintf("%s[%d]", index, i))
 			if err != nil {
 				return nil, err
 			}
@@ -61,10 +83,14 @@ func Slice(slice any, to string) (any, error) {
 		if !eval.CanConvert(typ) {
 			return nil, fmt.Errorf("slice: value of type %s cannot be converted to type %s", eval.Type(), typ)
 		}
+		cval, err := value(eval, typ, opts, fmt.Sprintf("%s[%d]", index, i))
+		if err != nil {
+			return nil, fmt.Errorf("slice: %w", err)
+		}
 		if rv.Kind() == reflect.Invalid {
 			rv = reflect.MakeSlice(reflect.SliceOf(typ), 0, val.Len())
 		}
-		rv = reflect.Append(rv, eval.Convert(typ))
+		rv = reflect.Append(rv, cval)
 	}
 	if rv.Kind() == reflect.Invalid {
 		return nil, nil
@@ -72,7 +98,7 @@ func Slice(slice any, to string) (any, error) {
 	return rv.Interface(), nil
 }
 
-func SliceStruct(slice any, to string) (any, error) {
+func SliceStruct(slice any, to string, opts *Options) (any, error) {
 	if slice == nil {
 		return nil, errors.New("slicestruct: got nil")
 	}
@@ -120,6 +146,11 @@ func SliceStruct(slice any, to string) (any, error) {
 		if err != nil {
 			return nil, err
 		}
+
+		opts, err = opts.forType(to)
+		if err != nil {
+			return nil, fmt.Errorf("slicestruct: %w", err)
+		}
 	}
 
 	nfields := []reflect.StructField{}
@@ -153,7 +184,11 @@ func SliceStruct(slice any, to string) (any, error) {
 			if !eeval.CanConvert(typ) {
 				return nil, fmt.Errorf("slicestruct: value of type %s cannot be converted to type %s", eeval.Type(), typ)
 			}
-			rvv.Field(j).Set(eeval.Convert(typ))
+			cval, err := value(eeval, typ, opts, fmt.Sprintf("[%d].%s", i, nf.Name))
+			if err != nil {
+				return nil, fmt.Errorf("slicestruct: %w", err)
+			}
+			rvv.Field(j).Set(cval)
 		}
 		rv = reflect.Append(rv, rvv)
 	}
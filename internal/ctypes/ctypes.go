@@ -66,6 +66,10 @@ func ToString(name string, obj any, hex bool) (string, error) {
 }
 
 func ToType(name string) (reflect.Type, error) {
+	if q, ok := ParseQFormat(name); ok {
+		name = q.Storage
+	}
+
 	ct, found := ctypes[name]
 	if !found {
 		return nil, fmt.Errorf("ctypes: type not supported: %s", name)
@@ -0,0 +1,76 @@
+package ctypes
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var reQFormat = regexp.MustCompile(`^(u?)q([0-9]+)(?:\.([0-9]+))?$`)
+
+// QFormat is a fixed-point format, named after the Q notation: `qN` and `qM.N` are signed
+// formats with M integer bits (0 if omitted) and N fractional bits, plus a sign bit.
+// `uqN` and `uqM.N` are the unsigned variants, without the sign bit.
+type QFormat struct {
+	Name           string
+	Signed         bool
+	IntegerBits    int
+	FractionalBits int
+	Storage        string
+}
+
+func ParseQFormat(name string) (*QFormat, bool) {
+	m := reQFormat.FindStringSubmatch(name)
+	if m == nil {
+		return nil, false
+	}
+
+	rv := &QFormat{
+		Name:   name,
+		Signed: m[1] == "",
+	}
+
+	if m[3] == "" {
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			return nil, false
+		}
+		rv.FractionalBits = n
+	} else {
+		i, err := strconv.Atoi(m[2])
+		if err != nil {
+			return nil, false
+		}
+		n, err := strconv.Atoi(m[3])
+		if err != nil {
+			return nil, false
+		}
+		rv.IntegerBits = i
+		rv.FractionalBits = n
+	}
+
+	bits := rv.Bits()
+	if bits < 1 || bits > 64 {
+		return nil, false
+	}
+
+	prefix := "int"
+	if !rv.Signed {
+		prefix = "uint"
+	}
+	for _, s := range []int{8, 16, 32, 64} {
+		if bits <= s {
+			rv.Storage = fmt.Sprintf("%s%d_t", prefix, s)
+			break
+		}
+	}
+	return rv, true
+}
+
+// Bits returns the number of bits used by the format, including the sign bit.
+func (q *QFormat) Bits() int {
+	if q.Signed {
+		return q.IntegerBits + q.FractionalBits + 1
+	}
+	return q.IntegerBits + q.FractionalBits
+}
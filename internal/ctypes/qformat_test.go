@@ -0,0 +1,121 @@
+package ctypes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected *QFormat
+	}{
+		{"q7", &QFormat{Name: "q7", Signed: true, IntegerBits: 0, FractionalBits: 7, Storage: "int8_t"}},
+		{"q15", &QFormat{Name: "q15", Signed: true, IntegerBits: 0, FractionalBits: 15, Storage: "int16_t"}},
+		{"q0.31", &QFormat{Name: "q0.31", Signed: true, IntegerBits: 0, FractionalBits: 31, Storage: "int32_t"}},
+		{"q1.14", &QFormat{Name: "q1.14", Signed: true, IntegerBits: 1, FractionalBits: 14, Storage: "int16_t"}},
+		{"q1.15", &QFormat{Name: "q1.15", Signed: true, IntegerBits: 1, FractionalBits: 15, Storage: "int32_t"}},
+		{"q63", &QFormat{Name: "q63", Signed: true, IntegerBits: 0, FractionalBits: 63, Storage: "int64_t"}},
+		{"q0", &QFormat{Name: "q0", Signed: true, IntegerBits: 0, FractionalBits: 0, Storage: "int8_t"}},
+		{"uq8", &QFormat{Name: "uq8", Signed: false, IntegerBits: 0, FractionalBits: 8, Storage: "uint8_t"}},
+		{"uq1.15", &QFormat{Name: "uq1.15", Signed: false, IntegerBits: 1, FractionalBits: 15, Storage: "uint16_t"}},
+		{"uq16.16", &QFormat{Name: "uq16.16", Signed: false, IntegerBits: 16, FractionalBits: 16, Storage: "uint32_t"}},
+		{"uq1.16", &QFormat{Name: "uq1.16", Signed: false, IntegerBits: 1, FractionalBits: 16, Storage: "uint32_t"}},
+		{"uq64", &QFormat{Name: "uq64", Signed: false, IntegerBits: 0, FractionalBits: 64, Storage: "uint64_t"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, ok := ParseQFormat(tt.name)
+			if !ok {
+				t.Fatal("format not parsed")
+			}
+			if !reflect.DeepEqual(q, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, q)
+			}
+		})
+	}
+}
+
+func TestParseQFormatInvalid(t *testing.T) {
+	for _, name := range []string{
+		"",
+		"q",
+		"uq",
+		"q.15",
+		"q1.",
+		"Q15",
+		"q-1",
+		"q15.x",
+		"int16_t",
+		"q64",
+		"q1.63",
+		"q32.32",
+		"uq0",
+		"uq0.0",
+		"uq65",
+		"uq32.33",
+		"q99999999999999999999",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if q, ok := ParseQFormat(name); ok {
+				t.Errorf("unexpected format: %+v", q)
+			}
+		})
+	}
+}
+
+func TestQFormatBits(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected int
+	}{
+		{"q15", 16},
+		{"q1.14", 16},
+		{"uq16.16", 32},
+		{"uq1.15", 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, ok := ParseQFormat(tt.name)
+			if !ok {
+				t.Fatal("format not parsed")
+			}
+			if b := q.Bits(); b != tt.expected {
+				t.Errorf("expected %d bits, got %d", tt.expected, b)
+			}
+		})
+	}
+}
+
+func TestToTypeQFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected reflect.Type
+	}{
+		{"q7", reflect.TypeOf(int8(0))},
+		{"q15", reflect.TypeOf(int16(0))},
+		{"q0.31", reflect.TypeOf(int32(0))},
+		{"q32.31", reflect.TypeOf(int64(0))},
+		{"uq1.15", reflect.TypeOf(uint16(0))},
+		{"uq16.16", reflect.TypeOf(uint32(0))},
+		{"uq33", reflect.TypeOf(uint64(0))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ, err := ToType(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if typ != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, typ)
+			}
+		})
+	}
+
+	if _, err := ToType("q64"); err == nil || err.Error() != "ctypes: type not supported: q64" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -3,21 +3,27 @@ package datareg
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"strings"
 
 	"rafaelmartins.com/p/synth-datagen/internal/convert"
+	"rafaelmartins.com/p/synth-datagen/internal/ctypes"
 	"rafaelmartins.com/p/synth-datagen/internal/selector"
 	"rafaelmartins.com/p/synth-datagen/internal/utils"
 )
 
 type DataReg struct {
 	global map[string]any
+	dir    string
 }
 
-func New(global map[string]any) *DataReg {
+// New creates a data registry with global parameters. Relative paths from fields tagged
+// with `path:"true"` are resolved against dir.
+func New(global map[string]any, dir string) *DataReg {
 	return &DataReg{
 		global: global,
+		dir:    dir,
 	}
 }
 
@@ -82,28 +88,39 @@ func (p *DataReg) Evaluate(mod string, obj any, local map[string]any, slt *selec
 			if found != "" {
 				return fmt.Errorf("datareg: parameter not defined: %s (or %s_%s, required by selector %q)", fn, mod, fn, found)
 			}
-			if k := field.Type.Kind(); k != reflect.Pointer && k != reflect.Slice {
-				return fmt.Errorf("datareg: parameter not defined: %s (or %s_%s, required, not a pointer nor a slice)", fn, mod, fn)
+			if k := field.Type.Kind(); k != reflect.Pointer && k != reflect.Slice && k != reflect.Map {
+				return fmt.Errorf("datareg: parameter not defined: %s (or %s_%s, required, not a pointer, a slice nor a map)", fn, mod, fn)
 			}
 			continue
 		}
 
 		v := reflect.ValueOf(itf)
 
+		t := field.Type
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
 		// yaml library returns a slice of interfaces instead of a slice of the underlying type
 		if vl, ok := itf.([]any); ok {
-			s, err := convert.Slice(vl, "")
+			// convert to the numeric field element type when possible, so that lists mixing integers
+			// and floats (e.g. [1, 0.5]) are not converted to the type of the first element
+			to := ""
+			if t.Kind() == reflect.Slice && ctypes.TypeIsNumeric(t.Elem()) {
+				if name, err := ctypes.FromType(t.Elem()); err == nil {
+					if typ, err := ctypes.ToType(name); err == nil && typ == t.Elem() {
+						to = name
+					}
+				}
+			}
+
+			s, err := convert.Slice(vl, to, &convert.Options{})
 			if err != nil {
 				return err
 			}
 			v = reflect.ValueOf(s)
 		}
 
-		t := field.Type
-		if t.Kind() == reflect.Pointer {
-			t = t.Elem()
-		}
-
 		if !v.CanConvert(t) {
 			return fmt.Errorf("datareg: invalid parameter value type: %s: parameter is %q, wants %q", utils.FieldNameToSnake(field.Name), v.Type(), t)
 		}
@@ -113,11 +130,16 @@ func (p *DataReg) Evaluate(mod string, obj any, local map[string]any, slt *selec
 			return fmt.Errorf("datareg: can't set value: %s", utils.FieldNameToSnake(field.Name))
 		}
 
+		cv := v.Convert(t)
+		if field.Tag.Get("path") == "true" && t.Kind() == reflect.String && p.dir != "" && !filepath.IsAbs(cv.String()) {
+			cv = reflect.ValueOf(filepath.Join(p.dir, cv.String())).Convert(t)
+		}
+
 		if fld.Kind() == reflect.Pointer {
 			fld.Set(reflect.New(t))
-			fld.Elem().Set(v.Convert(t))
+			fld.Elem().Set(cv)
 		} else {
-			fld.Set(v.Convert(t))
+			fld.Set(cv)
 		}
 	}
 	return nil
@@ -0,0 +1,42 @@
+package datareg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestEvaluatePath(t *testing.T) {
+	abs, err := filepath.Abs(filepath.Join("waveforms", "saw.wav"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		dir      string
+		value    string
+		expected string
+	}{
+		{"relative", "configs", "saw.wav", filepath.Join("configs", "saw.wav")},
+		{"relative_parent", "configs", "../saw.wav", "saw.wav"},
+		{"absolute", "configs", abs, abs},
+		{"no_dir", "", "saw.wav", "saw.wav"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			config := struct {
+				WavFile *string `path:"true"`
+				Name    string
+			}{}
+			dreg := New(map[string]any{"wav_file": tt.value}, tt.dir)
+			if err := dreg.Evaluate("mod", &config, map[string]any{"name": "saw.wav"}, nil); err != nil {
+				t.Fatal(err)
+			}
+			if config.WavFile == nil || *config.WavFile != tt.expected {
+				t.Errorf("expected %q, got %v", tt.expected, config.WavFile)
+			}
+			if config.Name != "saw.wav" {
+				t.Errorf("untagged field changed: %q", config.Name)
+			}
+		})
+	}
+}
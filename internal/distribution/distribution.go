@@ -0,0 +1,49 @@
+package distribution
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+func Values(name string, exponent float64, n int, min float64, max float64) ([]float64, error) {
+	if n < 1 {
+		return nil, errors.New("distribution: number of values must be >= 1")
+	}
+
+	// a single value is always min, but the distribution is still validated.
+	steps := float64(n - 1)
+	if n == 1 {
+		steps = 1
+	}
+
+	rv := make([]float64, 0, n)
+
+	switch name {
+	case "linear":
+		for i := 0.; i < float64(n); i++ {
+			rv = append(rv, min+(max-min)*i/steps)
+		}
+
+	case "logarithmic":
+		if min <= 0 || max <= 0 {
+			return nil, errors.New("distribution: logarithmic distribution requires positive min and max")
+		}
+		for i := 0.; i < float64(n); i++ {
+			rv = append(rv, min*math.Pow(max/min, i/steps))
+		}
+
+	case "exponential":
+		if exponent == 0 {
+			return nil, errors.New("distribution: exponential distribution requires non-zero exponent")
+		}
+		for i := 0.; i < float64(n); i++ {
+			rv = append(rv, min+(max-min)*(-1+math.Exp(exponent*i/steps))/(-1+math.Exp(exponent)))
+		}
+
+	default:
+		return nil, fmt.Errorf("distribution: invalid distribution: %s", name)
+	}
+
+	return rv, nil
+}
@@ -0,0 +1,67 @@
+package distribution
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValues(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		dist     string
+		exponent float64
+		n        int
+		min      float64
+		max      float64
+		expected []float64
+	}{
+		{"linear", "linear", 0, 5, 0, 100, []float64{0, 25, 50, 75, 100}},
+		{"linear_descending", "linear", 0, 3, 10, 0, []float64{10, 5, 0}},
+		{"logarithmic", "logarithmic", 0, 4, 10, 10000, []float64{10, 100, 1000, 10000}},
+		{"exponential", "exponential", 3, 3, 0, 1, []float64{0, (math.Exp(1.5) - 1) / (math.Exp(3) - 1), 1}},
+		{"exponential_negative", "exponential", -3, 3, 0, 1, []float64{0, (math.Exp(-1.5) - 1) / (math.Exp(-3) - 1), 1}},
+		{"single_linear", "linear", 0, 1, 20, 100, []float64{20}},
+		{"single_logarithmic", "logarithmic", 0, 1, 20, 100, []float64{20}},
+		{"single_exponential", "exponential", 3, 1, 20, 100, []float64{20}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Values(tt.dist, tt.exponent, tt.n, tt.min, tt.max)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %d values, got %d", len(tt.expected), len(got))
+			}
+			for i, v := range got {
+				if math.Abs(v-tt.expected[i]) > 1e-9 {
+					t.Errorf("value %d: expected %g, got %g", i, tt.expected[i], v)
+				}
+			}
+		})
+	}
+}
+
+func TestValuesErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		dist     string
+		exponent float64
+		n        int
+		min      float64
+		err      string
+	}{
+		{"count", "linear", 0, 0, 20, "distribution: number of values must be >= 1"},
+		{"invalid", "cubic", 0, 10, 20, "distribution: invalid distribution: cubic"},
+		{"invalid_single", "cubic", 0, 1, 20, "distribution: invalid distribution: cubic"},
+		{"logarithmic_min", "logarithmic", 0, 10, 0, "distribution: logarithmic distribution requires positive min and max"},
+		{"logarithmic_min_single", "logarithmic", 0, 1, 0, "distribution: logarithmic distribution requires positive min and max"},
+		{"exponential_exponent", "exponential", 0, 10, 20, "distribution: exponential distribution requires non-zero exponent"},
+		{"exponential_exponent_single", "exponential", 0, 1, 20, "distribution: exponential distribution requires non-zero exponent"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Values(tt.dist, tt.exponent, tt.n, tt.min, 100); err == nil || err.Error() != tt.err {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
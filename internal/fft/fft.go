@@ -0,0 +1,80 @@
+package fft
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+func transform(x []complex128, sign float64) []complex128 {
+	n := len(x)
+	rv := make([]complex128, n)
+	if n == 0 {
+		return rv
+	}
+
+	// lengths that are not a power of two are rare here (e.g. imported
+	// samples), and a direct dft is fast enough for them.
+	if n&(n-1) != 0 {
+		for k := range n {
+			for i, v := range x {
+				rv[k] += v * cmplx.Exp(complex(0, sign*2*math.Pi*float64(k*i%n)/float64(n)))
+			}
+		}
+		return rv
+	}
+
+	shift := 64 - bits.TrailingZeros(uint(n))
+	for i, v := range x {
+		rv[bits.Reverse64(uint64(i))>>shift] = v
+	}
+	if n == 1 {
+		return rv
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		w := cmplx.Exp(complex(0, sign*2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			wk := complex(1, 0)
+			for k := range size / 2 {
+				a := rv[start+k]
+				b := rv[start+k+size/2] * wk
+				rv[start+k] = a + b
+				rv[start+k+size/2] = a - b
+				wk *= w
+			}
+		}
+	}
+	return rv
+}
+
+// Forward returns the discrete fourier transform of x.
+func Forward(x []complex128) []complex128 {
+	return transform(x, -1)
+}
+
+// Inverse returns the inverse discrete fourier transform of x, scaled by 1/len(x).
+func Inverse(x []complex128) []complex128 {
+	rv := transform(x, 1)
+	for i := range rv {
+		rv[i] /= complex(float64(len(rv)), 0)
+	}
+	return rv
+}
+
+// Real converts a real signal to complex values.
+func Real(x []float64) []complex128 {
+	rv := make([]complex128, 0, len(x))
+	for _, v := range x {
+		rv = append(rv, complex(v, 0))
+	}
+	return rv
+}
+
+// NextPowerOfTwo returns the smallest power of two that is >= n.
+func NextPowerOfTwo(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
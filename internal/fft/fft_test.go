@@ -0,0 +1,50 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func dft(x []complex128) []complex128 {
+	rv := make([]complex128, len(x))
+	for k := range x {
+		for i, v := range x {
+			rv[k] += v * cmplx.Exp(complex(0, -2*math.Pi*float64(k)*float64(i)/float64(len(x))))
+		}
+	}
+	return rv
+}
+
+func TestForward(t *testing.T) {
+	for _, n := range []int{1, 2, 8, 64, 12, 7} {
+		x := make([]complex128, 0, n)
+		for i := range n {
+			x = append(x, complex(math.Sin(float64(i)*0.7)+0.1*float64(i), math.Cos(float64(i)*1.3)))
+		}
+
+		expected := dft(x)
+		result := Forward(x)
+		for k := range expected {
+			if cmplx.Abs(expected[k]-result[k]) > 1e-9 {
+				t.Errorf("n=%d: bin %d: expected %v, got %v", n, k, expected[k], result[k])
+			}
+		}
+
+		inv := Inverse(result)
+		for i := range x {
+			if cmplx.Abs(x[i]-inv[i]) > 1e-9 {
+				t.Errorf("n=%d: sample %d: expected %v, got %v", n, i, x[i], inv[i])
+			}
+		}
+	}
+}
+
+func TestNextPowerOfTwo(t *testing.T) {
+	tests := map[int]int{0: 1, 1: 1, 2: 2, 3: 4, 64: 64, 65: 128}
+	for n, expected := range tests {
+		if result := NextPowerOfTwo(n); result != expected {
+			t.Errorf("%d: expected %d, got %d", n, expected, result)
+		}
+	}
+}
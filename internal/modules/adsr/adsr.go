@@ -7,6 +7,8 @@ import (
 
 	"rafaelmartins.com/p/synth-datagen/internal/convert"
 	"rafaelmartins.com/p/synth-datagen/internal/datareg"
+	"rafaelmartins.com/p/synth-datagen/internal/distribution"
+	"rafaelmartins.com/p/synth-datagen/internal/quantization"
 	"rafaelmartins.com/p/synth-datagen/internal/renderer"
 	"rafaelmartins.com/p/synth-datagen/internal/selector"
 )
@@ -19,19 +21,25 @@ const (
 type ADSR struct{}
 
 type adsrConfig struct {
-	Samples                      int
-	DataAttributes               []string
-	SampleAmplitude              *float64 `selectors:"curves_as3310,curves_linear"`
-	SampleScalarType             *string  `selectors:"curves_as3310,curves_linear"`
-	SampleRate                   *float64 `selectors:"time_steps"`
-	TimeSteps                    *int     `selectors:"time_steps,descriptions"`
-	TimeStepsMinMs               *int     `selectors:"time_steps,descriptions"`
-	TimeStepsMaxMs               *int     `selectors:"time_steps,descriptions"`
-	TimeStepsScalarType          *string  `selectors:"time_steps"`
-	TimeStepsFractionalBitWidth  *uint8
-	LevelDescriptions            *int `selectors:"descriptions"`
-	LevelDescriptionsStringWidth *int
-	TimeDescriptionsStringWidth  *int
+	Samples                       int
+	DataAttributes                []string
+	SampleAmplitude               *float64 `selectors:"curves_as3310,curves_linear"`
+	SampleScalarType              *string  `selectors:"curves_as3310,curves_linear"`
+	SampleOffset                  *float64
+	SampleRate                    *float64 `selectors:"time_steps"`
+	TimeSteps                     *int     `selectors:"time_steps,descriptions"`
+	TimeStepsMinMs                *int     `selectors:"time_steps,descriptions"`
+	TimeStepsMaxMs                *int     `selectors:"time_steps,descriptions"`
+	TimeStepsScalarType           *string  `selectors:"time_steps"`
+	TimeStepsFractionalBitWidth   *uint8
+	TimeStepsDistribution         *string
+	TimeStepsDistributionExponent *float64
+	LevelDescriptions             *int `selectors:"descriptions"`
+	LevelDescriptionsStringWidth  *int
+	TimeDescriptionsStringWidth   *int
+	QuantizationReport            *string
+	Rounding                      *string
+	Overflow                      *string
 }
 
 func (*ADSR) GetName() string {
@@ -48,6 +56,25 @@ func (a *ADSR) Render(r renderer.Renderer, identifier string, dreg *datareg.Data
 		return err
 	}
 
+	copts, err := convert.NewOptions(config.Rounding, config.Overflow)
+	if err != nil {
+		return err
+	}
+
+	offset := 0.
+	if config.SampleOffset != nil && (slt.IsSelected("curves_as3310") || slt.IsSelected("curves_linear")) {
+		offset = *config.SampleOffset
+
+		min, max, err := convert.Limits(*config.SampleScalarType, copts)
+		if err != nil {
+			return err
+		}
+		if offset < min || offset+*config.SampleAmplitude > max {
+			return fmt.Errorf("modules: adsr: sample range [%g, %g] doesn't fit %s range [%g, %g]",
+				offset, offset+*config.SampleAmplitude, *config.SampleScalarType, min, max)
+		}
+	}
+
 	sampleBase := make([]float64, 0, config.Samples)
 	for i := 0; i < config.Samples; i++ {
 		sampleBase = append(sampleBase, float64(i)/(float64(config.Samples-1)))
@@ -80,48 +107,62 @@ func (a *ADSR) Render(r renderer.Renderer, identifier string, dreg *datareg.Data
 		attackCurve := make([]float64, 0, config.Samples)
 		releaseCurve := make([]float64, 0, config.Samples)
 		for i := 0; i < config.Samples; i++ {
-			attackCurve = append(attackCurve, *config.SampleAmplitude*baseAttackCurve[i]/baseAttackCurve[config.Samples-1])
-			releaseCurve = append(releaseCurve, *config.SampleAmplitude*baseCurve[i]/target)
+			attackCurve = append(attackCurve, *config.SampleAmplitude*baseAttackCurve[i]/baseAttackCurve[config.Samples-1]+offset)
+			releaseCurve = append(releaseCurve, *config.SampleAmplitude*baseCurve[i]/target+offset)
 		}
 
-		atk, err := convert.Slice(attackCurve, *config.SampleScalarType)
+		atk, err := convert.Slice(attackCurve, *config.SampleScalarType, copts.WithIdentifier(identifier+"_curve_as3310_attack"))
 		if err != nil {
 			return err
 		}
-		r.AddData(identifier+"_curve_as3310_attack", atk, config.DataAttributes, nil)
+		r.AddData(identifier+"_curve_as3310_attack", renderer.Typed{Type: *config.SampleScalarType, Value: atk}, config.DataAttributes, nil)
+		if err := quantization.Report(r, config.QuantizationReport, identifier+"_curve_as3310_attack", *config.SampleScalarType, copts, attackCurve, atk); err != nil {
+			return err
+		}
 
-		rel, err := convert.Slice(releaseCurve, *config.SampleScalarType)
+		rel, err := convert.Slice(releaseCurve, *config.SampleScalarType, copts.WithIdentifier(identifier+"_curve_as3310_decay_release"))
 		if err != nil {
 			return err
 		}
-		r.AddData(identifier+"_curve_as3310_decay_release", rel, config.DataAttributes, nil)
+		r.AddData(identifier+"_curve_as3310_decay_release", renderer.Typed{Type: *config.SampleScalarType, Value: rel}, config.DataAttributes, nil)
+		if err := quantization.Report(r, config.QuantizationReport, identifier+"_curve_as3310_decay_release", *config.SampleScalarType, copts, releaseCurve, rel); err != nil {
+			return err
+		}
 	}
 
 	if slt.IsSelected("curves_linear") {
 		linearCurve := make([]float64, 0, config.Samples)
 		for _, t := range sampleBase {
-			linearCurve = append(linearCurve, *config.SampleAmplitude*t)
+			linearCurve = append(linearCurve, *config.SampleAmplitude*t+offset)
 		}
 
-		lin, err := convert.Slice(linearCurve, *config.SampleScalarType)
+		lin, err := convert.Slice(linearCurve, *config.SampleScalarType, copts.WithIdentifier(identifier+"_curve_linear"))
 		if err != nil {
 			return err
 		}
-		r.AddData(identifier+"_curve_linear", lin, config.DataAttributes, nil)
+		r.AddData(identifier+"_curve_linear", renderer.Typed{Type: *config.SampleScalarType, Value: lin}, config.DataAttributes, nil)
+		if err := quantization.Report(r, config.QuantizationReport, identifier+"_curve_linear", *config.SampleScalarType, copts, linearCurve, lin); err != nil {
+			return err
+		}
 	}
 
 	times := []float64{}
 
 	if slt.IsSelected("time_steps") || slt.IsSelected("descriptions") {
-		tmpTimes := make([]float64, 0, *config.TimeSteps)
-		for i := 0.; i < float64(*config.TimeSteps); i++ {
-			tmpTimes = append(tmpTimes, -1.+math.Exp(6*i/(float64(*config.TimeSteps-1))))
+		dist := "exponential"
+		if config.TimeStepsDistribution != nil {
+			dist = *config.TimeStepsDistribution
 		}
 
-		dT := float64(*config.TimeStepsMaxMs - *config.TimeStepsMinMs)
-		times = make([]float64, 0, *config.TimeSteps)
-		for _, t := range tmpTimes {
-			times = append(times, float64(*config.TimeStepsMinMs)+(dT*t/tmpTimes[*config.TimeSteps-1]))
+		exponent := 6.
+		if config.TimeStepsDistributionExponent != nil {
+			exponent = *config.TimeStepsDistributionExponent
+		}
+
+		var err error
+		times, err = distribution.Values(dist, exponent, *config.TimeSteps, float64(*config.TimeStepsMinMs), float64(*config.TimeStepsMaxMs))
+		if err != nil {
+			return err
 		}
 	}
 
@@ -131,17 +172,15 @@ func (a *ADSR) Render(r renderer.Renderer, identifier string, dreg *datareg.Data
 			timeSteps = append(timeSteps, (float64(config.Samples)*1000.)/(t*(*config.SampleRate)))
 		}
 
-		if config.TimeStepsFractionalBitWidth != nil {
-			for idx := range timeSteps {
-				timeSteps[idx] *= float64(int(1) << *config.TimeStepsFractionalBitWidth)
-			}
-		}
-
-		ts, err := convert.Slice(timeSteps, *config.TimeStepsScalarType)
+		topts := copts.WithFractionalBitWidth(config.TimeStepsFractionalBitWidth)
+		ts, err := convert.Slice(timeSteps, *config.TimeStepsScalarType, topts.WithIdentifier(identifier+"_time_steps"))
 		if err != nil {
 			return err
 		}
-		r.AddData(identifier+"_time_steps", ts, config.DataAttributes, nil)
+		r.AddData(identifier+"_time_steps", renderer.Typed{Type: *config.TimeStepsScalarType, Value: ts}, config.DataAttributes, nil)
+		if err := quantization.Report(r, config.QuantizationReport, identifier+"_time_steps", *config.TimeStepsScalarType, topts, timeSteps, ts); err != nil {
+			return err
+		}
 	}
 
 	if slt.IsSelected("descriptions") {
@@ -0,0 +1,171 @@
+package filters
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+
+	"rafaelmartins.com/p/synth-datagen/internal/utils"
+)
+
+type coefficientSet map[string]float64
+
+// analyzer returns the largest pole radius of a coefficient set and the normalized cutoff
+// frequency it implements. The cutoff frequency is NaN if it can't be estimated.
+type analyzer func(c coefficientSet) (radius float64, nfreq float64)
+
+// coefficientSets reads back converted coefficient tables (1-D or 2-D slices of structs),
+// undoing the fixed-point scaling.
+func coefficientSets(value any, scale float64) [][]coefficientSet {
+	val := reflect.ValueOf(value)
+	if val.Kind() != reflect.Slice {
+		return nil
+	}
+
+	rows := []reflect.Value{val}
+	if val.Len() > 0 {
+		if v := reflect.ValueOf(val.Index(0).Interface()); v.Kind() == reflect.Slice {
+			rows = []reflect.Value{}
+			for i := 0; i < val.Len(); i++ {
+				rows = append(rows, reflect.ValueOf(val.Index(i).Interface()))
+			}
+		}
+	}
+
+	f64 := reflect.TypeOf(float64(0))
+	rv := make([][]coefficientSet, 0, len(rows))
+	for _, row := range rows {
+		sets := make([]coefficientSet, 0, row.Len())
+		for i := 0; i < row.Len(); i++ {
+			eval := reflect.ValueOf(row.Index(i).Interface())
+			set := coefficientSet{}
+			for _, field := range reflect.VisibleFields(eval.Type()) {
+				if field.IsExported() && eval.FieldByName(field.Name).CanConvert(f64) {
+					set[utils.FieldNameToSnake(field.Name)] = eval.FieldByName(field.Name).Convert(f64).Float() / scale
+				}
+			}
+			sets = append(sets, set)
+		}
+		rv = append(rv, sets)
+	}
+	return rv
+}
+
+// scalars reads back a converted 1-D coefficient table, undoing the fixed-point scaling.
+func scalars(value any, scale float64) []float64 {
+	val := reflect.ValueOf(value)
+	if val.Kind() != reflect.Slice {
+		return nil
+	}
+
+	f64 := reflect.TypeOf(float64(0))
+	rv := make([]float64, 0, val.Len())
+	for i := 0; i < val.Len(); i++ {
+		if eval := reflect.ValueOf(val.Index(i).Interface()); eval.CanConvert(f64) {
+			rv = append(rv, eval.Convert(f64).Float()/scale)
+		}
+	}
+	return rv
+}
+
+// polesRadius returns the largest root magnitude of z^2 - a1 * z - a2.
+func polesRadius(a1 float64, a2 float64) float64 {
+	disc := a1*a1 + 4*a2
+	if disc < 0 {
+		return math.Sqrt(-a2)
+	}
+	sq := math.Sqrt(disc)
+	return max(math.Abs((a1+sq)/2), math.Abs((a1-sq)/2))
+}
+
+func onepoleAnalyzer(c coefficientSet) (float64, float64) {
+	if c["a1"] <= -1 {
+		return math.Abs(c["a1"]), math.NaN()
+	}
+	return math.Abs(c["a1"]), math.Atan((1-c["a1"])/(1+c["a1"])) / math.Pi
+}
+
+func biquadAnalyzer(d *biquadDesign, gainDb float64) analyzer {
+	a := math.Pow(10, gainDb/40)
+	return func(c coefficientSet) (float64, float64) {
+		radius := polesRadius(c["a1"], c["a2"])
+		if c["a2"] >= 1 {
+			return radius, math.NaN()
+		}
+
+		cw0 := c["a1"] / (1 - c["a2"])
+		if d.cosW0 != nil {
+			cw0 = d.cosW0(cw0, a)
+		}
+		if cw0 < -1 || cw0 > 1 {
+			return radius, math.NaN()
+		}
+		return radius, math.Acos(cw0) / (2 * math.Pi)
+	}
+}
+
+func chamberlinAnalyzer(c coefficientSet) (float64, float64) {
+	radius := polesRadius(2-c["f"]*c["q"]-c["f"]*c["f"], -(1 - c["f"]*c["q"]))
+	if c["f"] < 0 || c["f"] > 2 {
+		return radius, math.NaN()
+	}
+	return radius, math.Asin(c["f"]/2) / math.Pi
+}
+
+func svfTptAnalyzer(c coefficientSet) (float64, float64) {
+	radius := polesRadius(2*(c["a1"]-c["a3"]), -((2*c["a1"]-1)*(1-2*c["a3"]) + 4*c["a2"]*c["a2"]))
+	if c["g"] <= 0 {
+		return max(radius, 1), math.NaN()
+	}
+	return radius, math.Atan(c["g"]) / math.Pi
+}
+
+func ladderTptAnalyzer(c coefficientSet) (float64, float64) {
+	// only the poles of the individual stages are checked, the feedback loop is
+	// expected to self-oscillate at high resonance settings.
+	radius := math.Abs(1 - 2*c["g"])
+	if c["g"] <= 0 || c["g"] >= 1 || c["a"] <= 0 {
+		return max(radius, 1), math.NaN()
+	}
+	return radius, math.Atan(c["g"]/(1-c["g"])) / math.Pi
+}
+
+func analyze(identifier string, sets [][]coefficientSet, freqs []float64, sampleRate float64, an analyzer) error {
+	maxRadius := 0.
+	maxError := math.NaN()
+
+	for j, row := range sets {
+		for i, set := range row {
+			idx := fmt.Sprintf("%d", i)
+			if len(sets) > 1 {
+				idx = fmt.Sprintf("[%d][%d]", j, i)
+			}
+
+			freq := math.NaN()
+			if i < len(freqs) {
+				freq = freqs[i]
+			}
+
+			radius, nfreq := an(set)
+			if math.IsNaN(radius) || radius >= 1 {
+				return fmt.Errorf("filters: %s: unstable coefficients at index %s (%.2fHz): pole radius %.6f", identifier, idx, freq, radius)
+			}
+			maxRadius = max(maxRadius, radius)
+
+			if !math.IsNaN(nfreq) && nfreq > 0 && freq > 0 {
+				cents := math.Abs(1200 * math.Log2(nfreq*sampleRate/freq))
+				if math.IsNaN(maxError) || cents > maxError {
+					maxError = cents
+				}
+			}
+		}
+	}
+
+	if math.IsNaN(maxError) {
+		log.Printf("filters: %s: max pole radius %.6f", identifier, maxRadius)
+	} else {
+		log.Printf("filters: %s: max pole radius %.6f, max cutoff error %.2f cents", identifier, maxRadius, maxError)
+	}
+	return nil
+}
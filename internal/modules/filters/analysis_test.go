@@ -0,0 +1,97 @@
+package filters
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"rafaelmartins.com/p/synth-datagen/internal/convert"
+)
+
+func biquadSets(t *testing.T, selector string, freqs []float64, sampleRate float64, to string) [][]coefficientSet {
+	t.Helper()
+	d := biquadDesignBySelector(t, selector)
+	bq := make([]filterBiquad, 0, len(freqs))
+	for _, freq := range freqs {
+		bq = append(bq, d.coefficients(freq/sampleRate, 1/math.Sqrt2, 0))
+	}
+	v, err := convert.SliceStruct(bq, to, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scale, err := convert.Scale(to, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return coefficientSets(v, scale)
+}
+
+func captureLog(t *testing.T, f func()) string {
+	t.Helper()
+	buf := bytes.Buffer{}
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	f()
+	return buf.String()
+}
+
+func TestAnalyzeStability(t *testing.T) {
+	freqs := []float64{8000, 50}
+	d := biquadDesignBySelector(t, "lowpass_biquad")
+
+	// the poles of a low cutoff frequency are too close to the unit circle for 4 fractional bits.
+	sets := biquadSets(t, "lowpass_biquad", freqs, 48000, "q3.4")
+	err := analyze("f_lowpass_biquad_coefficients", sets, freqs, 48000, biquadAnalyzer(d, 0))
+	if err == nil || err.Error() != "filters: f_lowpass_biquad_coefficients: unstable coefficients at index 1 (50.00Hz): pole radius 1.000000" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	sets = biquadSets(t, "lowpass_biquad", freqs, 48000, "q1.30")
+	captureLog(t, func() {
+		err = analyze("f_lowpass_biquad_coefficients", sets, freqs, 48000, biquadAnalyzer(d, 0))
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// the index includes the row for 2-D tables.
+	sets = [][]coefficientSet{{{"a1": 0.5}}, {{"a1": 0.5}, {"a1": -1}}}
+	err = analyze("f_lowpass_onepole_coefficients", sets, freqs, 48000, onepoleAnalyzer)
+	if err == nil || err.Error() != "filters: f_lowpass_onepole_coefficients: unstable coefficients at index [1][1] (50.00Hz): pole radius 1.000000" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAnalyzeCutoff(t *testing.T) {
+	nfreq := 1000. / 48000
+	a1 := (1 - math.Tan(math.Pi*nfreq)) / (1 + math.Tan(math.Pi*nfreq))
+
+	// the coefficients implement 1 kHz, the table claims a frequency 25 cents below.
+	freqs := []float64{1000 * math.Pow(2, -25./1200)}
+	var err error
+	out := captureLog(t, func() {
+		err = analyze("f_lowpass_onepole_coefficients", [][]coefficientSet{{{"a1": a1}}}, freqs, 48000, onepoleAnalyzer)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fmt.Sprintf("filters: f_lowpass_onepole_coefficients: max pole radius %.6f, max cutoff error 25.00 cents", math.Abs(a1))
+	if !strings.Contains(out, expected) {
+		t.Errorf("expected %q in log output, got %q", expected, out)
+	}
+
+	// every biquad design reports the frequency it was designed for.
+	for _, d := range biquadDesigns {
+		for _, gainDb := range []float64{-6, 0, 6} {
+			c := d.coefficients(nfreq, 2, gainDb)
+			_, got := biquadAnalyzer(&d, gainDb)(coefficientSet{"b0": c.B0, "b1": c.B1, "b2": c.B2, "a1": c.A1, "a2": c.A2})
+			if math.Abs(got-nfreq) > 1e-12 {
+				t.Errorf("%s: gain %g dB: expected cutoff %g, got %g", d.selector, gainDb, nfreq, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package filters
+
+import (
+	"math"
+)
+
+type filterBiquad struct {
+	B0 float64 `filter:"b0"`
+	B1 float64 `filter:"b1"`
+	B2 float64 `filter:"b2"`
+	A1 float64 `filter:"a1"`
+	A2 float64 `filter:"a2"`
+}
+
+type biquadDesign struct {
+	selector string
+	design   func(w0 float64, alpha float64, a float64) (b0, b1, b2, a0, a1, a2 float64)
+
+	// recovers cos(w0) from a1 / (1 - a2), when it is not equal to it.
+	cosW0 func(r float64, a float64) float64
+}
+
+// formulas from the "Cookbook formulae for audio EQ biquad filter coefficients",
+// by Robert Bristow-Johnson.
+var biquadDesigns = []biquadDesign{
+	{
+		selector: "lowpass_biquad",
+		design: func(w0 float64, alpha float64, a float64) (b0, b1, b2, a0, a1, a2 float64) {
+			cw0 := math.Cos(w0)
+			return (1 - cw0) / 2, 1 - cw0, (1 - cw0) / 2, 1 + alpha, -2 * cw0, 1 - alpha
+		},
+	},
+	{
+		selector: "highpass_biquad",
+		design: func(w0 float64, alpha float64, a float64) (b0, b1, b2, a0, a1, a2 float64) {
+			cw0 := math.Cos(w0)
+			return (1 + cw0) / 2, -(1 + cw0), (1 + cw0) / 2, 1 + alpha, -2 * cw0, 1 - alpha
+		},
+	},
+	{
+		// constant 0dB peak gain
+		selector: "bandpass_biquad",
+		design: func(w0 float64, alpha float64, a float64) (b0, b1, b2, a0, a1, a2 float64) {
+			cw0 := math.Cos(w0)
+			return alpha, 0, -alpha, 1 + alpha, -2 * cw0, 1 - alpha
+		},
+	},
+	{
+		selector: "notch_biquad",
+		design: func(w0 float64, alpha float64, a float64) (b0, b1, b2, a0, a1, a2 float64) {
+			cw0 := math.Cos(w0)
+			return 1, -2 * cw0, 1, 1 + alpha, -2 * cw0, 1 - alpha
+		},
+	},
+	{
+		selector: "peaking_biquad",
+		design: func(w0 float64, alpha float64, a float64) (b0, b1, b2, a0, a1, a2 float64) {
+			cw0 := math.Cos(w0)
+			return 1 + alpha*a, -2 * cw0, 1 - alpha*a, 1 + alpha/a, -2 * cw0, 1 - alpha/a
+		},
+	},
+	{
+		selector: "lowshelf_biquad",
+		design: func(w0 float64, alpha float64, a float64) (b0, b1, b2, a0, a1, a2 float64) {
+			cw0 := math.Cos(w0)
+			sa := 2 * math.Sqrt(a) * alpha
+			return a * ((a + 1) - (a-1)*cw0 + sa),
+				2 * a * ((a - 1) - (a+1)*cw0),
+				a * ((a + 1) - (a-1)*cw0 - sa),
+				(a + 1) + (a-1)*cw0 + sa,
+				-2 * ((a - 1) + (a+1)*cw0),
+				(a + 1) + (a-1)*cw0 - sa
+		},
+		cosW0: func(r float64, a float64) float64 {
+			return ((a - 1) - r*(a+1)) / (r*(a-1) - (a + 1))
+		},
+	},
+	{
+		selector: "highshelf_biquad",
+		design: func(w0 float64, alpha float64, a float64) (b0, b1, b2, a0, a1, a2 float64) {
+			cw0 := math.Cos(w0)
+			sa := 2 * math.Sqrt(a) * alpha
+			return a * ((a + 1) + (a-1)*cw0 + sa),
+				-2 * a * ((a - 1) + (a+1)*cw0),
+				a * ((a + 1) + (a-1)*cw0 - sa),
+				(a + 1) - (a-1)*cw0 + sa,
+				2 * ((a - 1) - (a+1)*cw0),
+				(a + 1) - (a-1)*cw0 - sa
+		},
+		cosW0: func(r float64, a float64) float64 {
+			return (r*(a+1) + (a - 1)) / ((a + 1) + r*(a-1))
+		},
+	},
+}
+
+func (d *biquadDesign) coefficients(nfreq float64, q float64, gainDb float64) filterBiquad {
+	w0 := 2 * math.Pi * nfreq
+	alpha := math.Sin(w0) / (2 * q)
+	a := math.Pow(10, gainDb/40)
+
+	b0, b1, b2, a0, a1, a2 := d.design(w0, alpha, a)
+
+	// feedback coefficients are negated, to match the one-pole difference equation:
+	// y[n] = b0 * x[n] + b1 * x[n-1] + b2 * x[n-2] + a1 * y[n-1] + a2 * y[n-2]
+	return filterBiquad{
+		B0: b0 / a0,
+		B1: b1 / a0,
+		B2: b2 / a0,
+		A1: -a1 / a0,
+		A2: -a2 / a0,
+	}
+}
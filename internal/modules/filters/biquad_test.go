@@ -0,0 +1,136 @@
+package filters
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func biquadDesignBySelector(t *testing.T, selector string) *biquadDesign {
+	t.Helper()
+	for i := range biquadDesigns {
+		if biquadDesigns[i].selector == selector {
+			return &biquadDesigns[i]
+		}
+	}
+	t.Fatalf("biquad design not found: %s", selector)
+	return nil
+}
+
+func biquadGain(c filterBiquad, nfreq float64) float64 {
+	return cmplx.Abs(directForm([]float64{c.B0, c.B1, c.B2}, []float64{c.A1, c.A2}, nfreq))
+}
+
+func TestBiquadCoefficients(t *testing.T) {
+	// 1 kHz at 48 kHz, Q = 1/sqrt(2), computed from the cookbook formulas.
+	for _, tt := range []struct {
+		selector string
+		gainDb   float64
+		expected filterBiquad
+	}{
+		{"lowpass_biquad", 0, filterBiquad{
+			B0: 0.003916126660547383,
+			B1: 0.007832253321094766,
+			B2: 0.003916126660547383,
+			A1: 1.815341082704568,
+			A2: -0.8310055893467576,
+		}},
+		{"peaking_biquad", 6, filterBiquad{
+			B0: 1.0610424252634374,
+			B1: -1.8612731439964758,
+			B2: 0.816291571321481,
+			A1: 1.8612731439964758,
+			A2: -0.8773339965849185,
+		}},
+	} {
+		c := biquadDesignBySelector(t, tt.selector).coefficients(1000./48000, 1/math.Sqrt2, tt.gainDb)
+		for _, v := range []struct {
+			name          string
+			got, expected float64
+		}{
+			{"b0", c.B0, tt.expected.B0},
+			{"b1", c.B1, tt.expected.B1},
+			{"b2", c.B2, tt.expected.B2},
+			{"a1", c.A1, tt.expected.A1},
+			{"a2", c.A2, tt.expected.A2},
+		} {
+			if math.Abs(v.got-v.expected) > 1e-12 {
+				t.Errorf("%s: %s: expected %.16g, got %.16g", tt.selector, v.name, v.expected, v.got)
+			}
+		}
+	}
+}
+
+func TestBiquadNormalization(t *testing.T) {
+	nfreq, q, gainDb := 0.05, 2., 9.
+	w0 := 2 * math.Pi * nfreq
+	alpha := math.Sin(w0) / (2 * q)
+	a := math.Pow(10, gainDb/40)
+
+	for _, d := range biquadDesigns {
+		b0, b1, b2, a0, a1, a2 := d.design(w0, alpha, a)
+		if a0 == 1 {
+			t.Fatalf("%s: a0 is already 1, normalization not covered", d.selector)
+		}
+
+		c := d.coefficients(nfreq, q, gainDb)
+		for _, v := range []struct {
+			name          string
+			got, expected float64
+		}{
+			{"b0", c.B0 * a0, b0},
+			{"b1", c.B1 * a0, b1},
+			{"b2", c.B2 * a0, b2},
+			{"a1", -c.A1 * a0, a1},
+			{"a2", -c.A2 * a0, a2},
+		} {
+			if math.Abs(v.got-v.expected) > 1e-12*math.Max(1, math.Abs(v.expected)) {
+				t.Errorf("%s: %s: expected %g / a0, got %g", d.selector, v.name, v.expected, v.got/a0)
+			}
+		}
+	}
+}
+
+func TestBiquadGain(t *testing.T) {
+	nfreq, gainDb := 1000./48000, 6.
+	shelf := math.Pow(10, gainDb/20)
+
+	for _, q := range []float64{0.5, 1 / math.Sqrt2, 4} {
+		for _, tt := range []struct {
+			selector string
+			nfreq    float64
+			expected float64
+		}{
+			{"lowpass_biquad", 0, 1},
+			{"lowpass_biquad", 0.5, 0},
+			{"highpass_biquad", 0, 0},
+			{"highpass_biquad", 0.5, 1},
+			{"bandpass_biquad", nfreq, 1},
+			{"bandpass_biquad", 0, 0},
+			{"bandpass_biquad", 0.5, 0},
+			{"notch_biquad", nfreq, 0},
+			{"notch_biquad", 0, 1},
+			{"notch_biquad", 0.5, 1},
+			{"peaking_biquad", nfreq, shelf},
+			{"peaking_biquad", 0, 1},
+			{"peaking_biquad", 0.5, 1},
+			{"lowshelf_biquad", 0, shelf},
+			{"lowshelf_biquad", 0.5, 1},
+			{"highshelf_biquad", 0, 1},
+			{"highshelf_biquad", 0.5, shelf},
+		} {
+			c := biquadDesignBySelector(t, tt.selector).coefficients(nfreq, q, gainDb)
+			if g := biquadGain(c, tt.nfreq); math.Abs(g-tt.expected) > 1e-9 {
+				t.Errorf("%s: q=%g: gain at %g: expected %g, got %g", tt.selector, q, tt.nfreq, tt.expected, g)
+			}
+		}
+	}
+
+	// the bandpass peak is at the center frequency.
+	c := biquadDesignBySelector(t, "bandpass_biquad").coefficients(nfreq, 2, 0)
+	for _, f := range []float64{nfreq * 0.9, nfreq * 1.1} {
+		if g := biquadGain(c, f); g >= 1 {
+			t.Errorf("bandpass_biquad: gain at %g above the peak: %g", f, g)
+		}
+	}
+}
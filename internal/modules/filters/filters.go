@@ -1,26 +1,53 @@
 package filters
 
 import (
+	"errors"
 	"fmt"
 	"math"
 
 	"rafaelmartins.com/p/synth-datagen/internal/convert"
 	"rafaelmartins.com/p/synth-datagen/internal/datareg"
+	"rafaelmartins.com/p/synth-datagen/internal/distribution"
+	"rafaelmartins.com/p/synth-datagen/internal/quantization"
 	"rafaelmartins.com/p/synth-datagen/internal/renderer"
 	"rafaelmartins.com/p/synth-datagen/internal/selector"
 )
 
+const (
+	a4Frequency  = 440.0
+	a4MidiNumber = 69
+)
+
 type Filters struct{}
 
 type filtersConfig struct {
 	SampleRate                            float64
 	DataAttributes                        []string
-	Frequencies                           int
-	FrequencyMax                          float64
-	FrequencyMin                          float64
+	Frequencies                           *int
+	FrequencyMax                          *float64
+	FrequencyMin                          *float64
+	FrequencyDistribution                 *string
+	FrequencyDistributionExponent         *float64
+	A4Frequency                           *float64
+	NoteMin                               *int
+	NoteMax                               *int
 	FrequencyDescriptionsStringWidth      *int
 	CoefficientsOnepoleScalarType         *string `selectors:"lowpass_onepole,highpass_onepole"`
 	CoefficientsOnepoleFractionalBitWidth *uint8
+	Resonances                            *int     `selectors:"lowpass_biquad,highpass_biquad,bandpass_biquad,notch_biquad,peaking_biquad,lowshelf_biquad,highshelf_biquad,svf_chamberlin,svf_tpt,ladder_tpt"`
+	QMin                                  *float64 `selectors:"lowpass_biquad,highpass_biquad,bandpass_biquad,notch_biquad,peaking_biquad,lowshelf_biquad,highshelf_biquad,svf_chamberlin,svf_tpt"`
+	QMax                                  *float64 `selectors:"lowpass_biquad,highpass_biquad,bandpass_biquad,notch_biquad,peaking_biquad,lowshelf_biquad,highshelf_biquad,svf_chamberlin,svf_tpt"`
+	BiquadGainDb                          *float64 `selectors:"peaking_biquad,lowshelf_biquad,highshelf_biquad"`
+	CoefficientsBiquadScalarType          *string  `selectors:"lowpass_biquad,highpass_biquad,bandpass_biquad,notch_biquad,peaking_biquad,lowshelf_biquad,highshelf_biquad"`
+	CoefficientsBiquadFractionalBitWidth  *uint8
+	CoefficientsSvfScalarType             *string `selectors:"svf_chamberlin,svf_tpt"`
+	CoefficientsSvfFractionalBitWidth     *uint8
+	LadderFeedbackMax                     *float64
+	CoefficientsLadderScalarType          *string `selectors:"ladder_tpt"`
+	CoefficientsLadderFractionalBitWidth  *uint8
+	QuantizationReport                    *string
+	Rounding                              *string
+	Overflow                              *string
 }
 
 func (*Filters) GetName() string {
@@ -28,13 +55,84 @@ func (*Filters) GetName() string {
 }
 
 func (*Filters) GetAllowedSelectors() []string {
-	return []string{"lowpass_onepole", "highpass_onepole", "descriptions"}
+	return []string{
+		"lowpass_onepole",
+		"highpass_onepole",
+		"lowpass_biquad",
+		"highpass_biquad",
+		"bandpass_biquad",
+		"notch_biquad",
+		"peaking_biquad",
+		"lowshelf_biquad",
+		"highshelf_biquad",
+		"svf_chamberlin",
+		"svf_tpt",
+		"ladder_tpt",
+		"descriptions",
+	}
+}
+
+func qValues(config *filtersConfig) ([]float64, error) {
+	if *config.Resonances < 1 {
+		return nil, errors.New("filters: resonances must be >= 1")
+	}
+	if *config.QMin <= 0 || *config.QMax < *config.QMin {
+		return nil, errors.New("filters: q_min must be > 0 and <= q_max")
+	}
+
+	rv := make([]float64, 0, *config.Resonances)
+	for i := 0; i < *config.Resonances; i++ {
+		if *config.Resonances == 1 {
+			rv = append(rv, *config.QMin)
+			break
+		}
+		rv = append(rv, *config.QMin*math.Pow(*config.QMax / *config.QMin, float64(i)/float64(*config.Resonances-1)))
+	}
+	return rv, nil
+}
+
+// midiNotes returns the first note and the frequencies of the midi notes between note_min and
+// note_max. note_max defaults to the highest note below the nyquist frequency.
+func midiNotes(config *filtersConfig) (int, []float64, error) {
+	a4Freq := a4Frequency
+	if config.A4Frequency != nil {
+		a4Freq = *config.A4Frequency
+	}
+	freq := func(note int) float64 {
+		return a4Freq * math.Pow(2, float64(note-a4MidiNumber)/12)
+	}
+
+	noteMin := 0
+	if config.NoteMin != nil {
+		noteMin = *config.NoteMin
+	}
+	noteMax := 127
+	if config.NoteMax != nil {
+		noteMax = *config.NoteMax
+	} else {
+		for noteMax > noteMin && freq(noteMax) >= config.SampleRate/2 {
+			noteMax--
+		}
+	}
+	if noteMin < 0 || noteMax > 127 || noteMin > noteMax {
+		return 0, nil, errors.New("filters: note_min and note_max must be >= 0, <= 127, and note_min must be <= note_max")
+	}
+
+	rv := make([]float64, 0, noteMax-noteMin+1)
+	for note := noteMin; note <= noteMax; note++ {
+		f := freq(note)
+		if f >= config.SampleRate/2 {
+			return 0, nil, fmt.Errorf("filters: midi note %d frequency (%.2f Hz) must be below the nyquist frequency (%.2f Hz), reduce note_max", note, f, config.SampleRate/2)
+		}
+		rv = append(rv, f)
+	}
+	return noteMin, rv, nil
 }
 
 type filter1Pole struct {
-	A1 float64
-	B0 float64
-	B1 float64
+	A1 float64 `filter:"a1"`
+	B0 float64 `filter:"b0"`
+	B1 float64 `filter:"b1"`
 }
 
 func (f *Filters) Render(r renderer.Renderer, identifier string, dreg *datareg.DataReg, pmt map[string]any, slt *selector.Selector) error {
@@ -43,63 +141,315 @@ func (f *Filters) Render(r renderer.Renderer, identifier string, dreg *datareg.D
 		return err
 	}
 
-	tmpFreqs := make([]float64, 0, config.Frequencies)
-	for i := 0.; i < float64(config.Frequencies); i++ {
-		tmpFreqs = append(tmpFreqs, -1+math.Exp(3*i/float64(config.Frequencies-1)))
+	copts, err := convert.NewOptions(config.Rounding, config.Overflow)
+	if err != nil {
+		return err
 	}
 
-	dF := config.FrequencyMax - config.FrequencyMin
-	freqs := make([]float64, 0, config.Frequencies)
-	nfreqs := make([]float64, 0, config.Frequencies)
-	for _, fr := range tmpFreqs {
-		freq := config.FrequencyMin + dF*fr/tmpFreqs[config.Frequencies-1]
-		freqs = append(freqs, freq)
-		nfreqs = append(nfreqs, freq/config.SampleRate)
+	dist := "exponential"
+	if config.FrequencyDistribution != nil {
+		dist = *config.FrequencyDistribution
+	}
+
+	freqs := []float64{}
+	if dist == "midi_notes" {
+		noteMin, notes, err := midiNotes(&config)
+		if err != nil {
+			return err
+		}
+		freqs = notes
+		r.AddMacro(identifier+"_note_min", noteMin, false, false)
+		r.AddMacro(identifier+"_note_max", noteMin+len(freqs)-1, false, false)
+	} else {
+		if config.Frequencies == nil || config.FrequencyMin == nil || config.FrequencyMax == nil {
+			return fmt.Errorf("filters: frequencies, frequency_min and frequency_max are required by the %s distribution", dist)
+		}
+
+		exponent := 3.
+		if config.FrequencyDistributionExponent != nil {
+			exponent = *config.FrequencyDistributionExponent
+		}
+
+		var err error
+		freqs, err = distribution.Values(dist, exponent, *config.Frequencies, *config.FrequencyMin, *config.FrequencyMax)
+		if err != nil {
+			return err
+		}
 	}
 
-	bw := 0
-	if config.CoefficientsOnepoleFractionalBitWidth != nil {
-		bw = int(*config.CoefficientsOnepoleFractionalBitWidth)
+	nfreqs := make([]float64, 0, len(freqs))
+	for _, freq := range freqs {
+		nfreqs = append(nfreqs, freq/config.SampleRate)
 	}
 
+	oopts := copts.WithFractionalBitWidth(config.CoefficientsOnepoleFractionalBitWidth)
+
 	if slt.IsSelected("lowpass_onepole") {
-		lp := make([]filter1Pole, 0, config.Frequencies)
+		lp := make([]filter1Pole, 0, len(freqs))
 		for _, freq := range nfreqs {
 			a1 := (1. - math.Tan(math.Pi*freq)) / (1. + math.Tan(math.Pi*freq))
 			b0 := (1 - a1) / 2
 			lp = append(lp, filter1Pole{
-				A1: a1 * float64(int(1)<<bw),
-				B0: b0 * float64(int(1)<<bw),
-				B1: b0 * float64(int(1)<<bw),
+				A1: a1,
+				B0: b0,
+				B1: b0,
 			})
 		}
-		v, err := convert.SliceStruct(lp, *config.CoefficientsOnepoleScalarType)
+		v, err := convert.SliceStruct(lp, *config.CoefficientsOnepoleScalarType, oopts.WithIdentifier(identifier+"_lowpass_onepole_coefficients"))
 		if err != nil {
 			return err
 		}
-		r.AddData(identifier+"_lowpass_onepole_coefficients", v, config.DataAttributes, nil)
+		scale, err := convert.Scale(*config.CoefficientsOnepoleScalarType, oopts)
+		if err != nil {
+			return err
+		}
+		if err := analyze(identifier+"_lowpass_onepole_coefficients", coefficientSets(v, scale), freqs, config.SampleRate, onepoleAnalyzer); err != nil {
+			return err
+		}
+		r.AddData(identifier+"_lowpass_onepole_coefficients", renderer.Typed{Type: *config.CoefficientsOnepoleScalarType, StructType: identifier + "_onepole_coefficients", Value: v}, config.DataAttributes, nil)
+		if err := quantization.Report(r, config.QuantizationReport, identifier+"_lowpass_onepole_coefficients", *config.CoefficientsOnepoleScalarType, oopts, lp, v); err != nil {
+			return err
+		}
+		renderer.AddFilterResponse(r, identifier+"_lowpass_onepole_coefficients", v, config.SampleRate, scale)
 	}
 
 	if slt.IsSelected("highpass_onepole") {
-		hp := make([]filter1Pole, 0, config.Frequencies)
+		hp := make([]filter1Pole, 0, len(freqs))
 		for _, freq := range nfreqs {
 			a1 := (1. - math.Tan(math.Pi*freq)) / (1. + math.Tan(math.Pi*freq))
 			b0 := (1 + a1) / 2
 			hp = append(hp, filter1Pole{
-				A1: a1 * float64(int(1)<<bw),
-				B0: b0 * float64(int(1)<<bw),
-				B1: -b0 * float64(int(1)<<bw),
+				A1: a1,
+				B0: b0,
+				B1: -b0,
 			})
 		}
-		v, err := convert.SliceStruct(hp, *config.CoefficientsOnepoleScalarType)
+		v, err := convert.SliceStruct(hp, *config.CoefficientsOnepoleScalarType, oopts.WithIdentifier(identifier+"_highpass_onepole_coefficients"))
+		if err != nil {
+			return err
+		}
+		scale, err := convert.Scale(*config.CoefficientsOnepoleScalarType, oopts)
+		if err != nil {
+			return err
+		}
+		if err := analyze(identifier+"_highpass_onepole_coefficients", coefficientSets(v, scale), freqs, config.SampleRate, onepoleAnalyzer); err != nil {
+			return err
+		}
+		r.AddData(identifier+"_highpass_onepole_coefficients", renderer.Typed{Type: *config.CoefficientsOnepoleScalarType, StructType: identifier + "_onepole_coefficients", Value: v}, config.DataAttributes, nil)
+		if err := quantization.Report(r, config.QuantizationReport, identifier+"_highpass_onepole_coefficients", *config.CoefficientsOnepoleScalarType, oopts, hp, v); err != nil {
+			return err
+		}
+		renderer.AddFilterResponse(r, identifier+"_highpass_onepole_coefficients", v, config.SampleRate, scale)
+	}
+
+	qs := []float64{}
+	for _, design := range biquadDesigns {
+		if !slt.IsSelected(design.selector) {
+			continue
+		}
+
+		if len(qs) == 0 {
+			var err error
+			qs, err = qValues(&config)
+			if err != nil {
+				return err
+			}
+		}
+
+		gainDb := 0.
+		if config.BiquadGainDb != nil {
+			gainDb = *config.BiquadGainDb
+		}
+
+		bopts := copts.WithFractionalBitWidth(config.CoefficientsBiquadFractionalBitWidth)
+
+		src := make([][]filterBiquad, 0, len(qs))
+		rv := make([]any, 0, len(qs))
+		for j, q := range qs {
+			bq := make([]filterBiquad, 0, len(freqs))
+			for _, freq := range nfreqs {
+				c := design.coefficients(freq, q, gainDb)
+				bq = append(bq, filterBiquad{
+					B0: c.B0,
+					B1: c.B1,
+					B2: c.B2,
+					A1: c.A1,
+					A2: c.A2,
+				})
+			}
+			src = append(src, bq)
+
+			v, err := convert.SliceStruct(bq, *config.CoefficientsBiquadScalarType, bopts.WithIdentifier(fmt.Sprintf("%s_%s_coefficients[%d]", identifier, design.selector, j)))
+			if err != nil {
+				return err
+			}
+			rv = append(rv, v)
+		}
+		scale, err := convert.Scale(*config.CoefficientsBiquadScalarType, bopts)
+		if err != nil {
+			return err
+		}
+		if err := analyze(identifier+"_"+design.selector+"_coefficients", coefficientSets(rv, scale), freqs, config.SampleRate, biquadAnalyzer(&design, gainDb)); err != nil {
+			return err
+		}
+		r.AddData(identifier+"_"+design.selector+"_coefficients", renderer.Typed{Type: *config.CoefficientsBiquadScalarType, StructType: identifier + "_biquad_coefficients", Value: rv}, config.DataAttributes, nil)
+		if err := quantization.Report(r, config.QuantizationReport, identifier+"_"+design.selector+"_coefficients", *config.CoefficientsBiquadScalarType, bopts, src, rv); err != nil {
+			return err
+		}
+		renderer.AddFilterResponse(r, identifier+"_"+design.selector+"_coefficients", rv, config.SampleRate, scale)
+	}
+
+	if slt.IsSelected("svf_chamberlin") || slt.IsSelected("svf_tpt") {
+		if len(qs) == 0 {
+			var err error
+			qs, err = qValues(&config)
+			if err != nil {
+				return err
+			}
+		}
+
+		sopts := copts.WithFractionalBitWidth(config.CoefficientsSvfFractionalBitWidth)
+
+		if slt.IsSelected("svf_chamberlin") {
+			f := make([]float64, 0, len(freqs))
+			for _, freq := range nfreqs {
+				f = append(f, chamberlinF(freq))
+			}
+			vf, err := convert.Slice(f, *config.CoefficientsSvfScalarType, sopts.WithIdentifier(identifier+"_svf_chamberlin_f"))
+			if err != nil {
+				return err
+			}
+
+			q := make([]float64, 0, len(qs))
+			for _, qv := range qs {
+				q = append(q, 1/qv)
+			}
+			vq, err := convert.Slice(q, *config.CoefficientsSvfScalarType, sopts.WithIdentifier(identifier+"_svf_chamberlin_q"))
+			if err != nil {
+				return err
+			}
+
+			scale, err := convert.Scale(*config.CoefficientsSvfScalarType, sopts)
+			if err != nil {
+				return err
+			}
+
+			sets := [][]coefficientSet{}
+			for _, qv := range scalars(vq, scale) {
+				row := []coefficientSet{}
+				for _, fv := range scalars(vf, scale) {
+					row = append(row, coefficientSet{"f": fv, "q": qv})
+				}
+				sets = append(sets, row)
+			}
+			if err := analyze(identifier+"_svf_chamberlin", sets, freqs, config.SampleRate, chamberlinAnalyzer); err != nil {
+				return err
+			}
+			renderer.AddFilterResponse(r, identifier+"_svf_chamberlin", responses(sets, chamberlinResponse), config.SampleRate, 1)
+
+			r.AddData(identifier+"_svf_chamberlin_f", renderer.Typed{Type: *config.CoefficientsSvfScalarType, Value: vf}, config.DataAttributes, nil)
+			if err := quantization.Report(r, config.QuantizationReport, identifier+"_svf_chamberlin_f", *config.CoefficientsSvfScalarType, sopts, f, vf); err != nil {
+				return err
+			}
+			r.AddData(identifier+"_svf_chamberlin_q", renderer.Typed{Type: *config.CoefficientsSvfScalarType, Value: vq}, config.DataAttributes, nil)
+			if err := quantization.Report(r, config.QuantizationReport, identifier+"_svf_chamberlin_q", *config.CoefficientsSvfScalarType, sopts, q, vq); err != nil {
+				return err
+			}
+		}
+
+		if slt.IsSelected("svf_tpt") {
+			src := make([][]filterSvfTpt, 0, len(qs))
+			rv := make([]any, 0, len(qs))
+			for j, q := range qs {
+				svf := make([]filterSvfTpt, 0, len(freqs))
+				for _, freq := range nfreqs {
+					c := svfTptCoefficients(freq, q)
+					svf = append(svf, filterSvfTpt{
+						G:  c.G,
+						K:  c.K,
+						A1: c.A1,
+						A2: c.A2,
+						A3: c.A3,
+					})
+				}
+				src = append(src, svf)
+
+				v, err := convert.SliceStruct(svf, *config.CoefficientsSvfScalarType, sopts.WithIdentifier(fmt.Sprintf("%s_svf_tpt_coefficients[%d]", identifier, j)))
+				if err != nil {
+					return err
+				}
+				rv = append(rv, v)
+			}
+			scale, err := convert.Scale(*config.CoefficientsSvfScalarType, sopts)
+			if err != nil {
+				return err
+			}
+			sets := coefficientSets(rv, scale)
+			if err := analyze(identifier+"_svf_tpt_coefficients", sets, freqs, config.SampleRate, svfTptAnalyzer); err != nil {
+				return err
+			}
+			renderer.AddFilterResponse(r, identifier+"_svf_tpt_coefficients", responses(sets, svfTptResponse), config.SampleRate, 1)
+			r.AddData(identifier+"_svf_tpt_coefficients", renderer.Typed{Type: *config.CoefficientsSvfScalarType, Value: rv}, config.DataAttributes, nil)
+			if err := quantization.Report(r, config.QuantizationReport, identifier+"_svf_tpt_coefficients", *config.CoefficientsSvfScalarType, sopts, src, rv); err != nil {
+				return err
+			}
+		}
+	}
+
+	if slt.IsSelected("ladder_tpt") {
+		if *config.Resonances < 1 {
+			return errors.New("filters: resonances must be >= 1")
+		}
+
+		kMax := ladderFeedbackMax
+		if config.LadderFeedbackMax != nil {
+			kMax = *config.LadderFeedbackMax
+		}
+
+		lopts := copts.WithFractionalBitWidth(config.CoefficientsLadderFractionalBitWidth)
+
+		src := make([][]filterLadderTpt, 0, *config.Resonances)
+		rv := make([]any, 0, *config.Resonances)
+		for i := 0; i < *config.Resonances; i++ {
+			k := 0.
+			if *config.Resonances > 1 {
+				k = kMax * float64(i) / float64(*config.Resonances-1)
+			}
+
+			ladder := make([]filterLadderTpt, 0, len(freqs))
+			for _, freq := range nfreqs {
+				c := ladderTptCoefficients(freq, k)
+				ladder = append(ladder, filterLadderTpt{
+					G: c.G,
+					K: c.K,
+					A: c.A,
+				})
+			}
+			src = append(src, ladder)
+
+			v, err := convert.SliceStruct(ladder, *config.CoefficientsLadderScalarType, lopts.WithIdentifier(fmt.Sprintf("%s_ladder_tpt_coefficients[%d]", identifier, i)))
+			if err != nil {
+				return err
+			}
+			rv = append(rv, v)
+		}
+		scale, err := convert.Scale(*config.CoefficientsLadderScalarType, lopts)
 		if err != nil {
 			return err
 		}
-		r.AddData(identifier+"_highpass_onepole_coefficients", v, config.DataAttributes, nil)
+		sets := coefficientSets(rv, scale)
+		if err := analyze(identifier+"_ladder_tpt_coefficients", sets, freqs, config.SampleRate, ladderTptAnalyzer); err != nil {
+			return err
+		}
+		renderer.AddFilterResponse(r, identifier+"_ladder_tpt_coefficients", responses(sets, ladderTptResponse), config.SampleRate, 1)
+		r.AddData(identifier+"_ladder_tpt_coefficients", renderer.Typed{Type: *config.CoefficientsLadderScalarType, Value: rv}, config.DataAttributes, nil)
+		if err := quantization.Report(r, config.QuantizationReport, identifier+"_ladder_tpt_coefficients", *config.CoefficientsLadderScalarType, lopts, src, rv); err != nil {
+			return err
+		}
 	}
 
 	if slt.IsSelected("descriptions") {
-		desc := make([]string, 0, config.Frequencies)
+		desc := make([]string, 0, len(freqs))
 		for _, freq := range freqs {
 			if freq > 1000 {
 				desc = append(desc, fmt.Sprintf("%.2fkHz", freq/1000))
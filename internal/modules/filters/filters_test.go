@@ -0,0 +1,69 @@
+package filters
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMidiNotes(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	for _, tt := range []struct {
+		name       string
+		sampleRate float64
+		noteMin    *int
+		noteMax    *int
+		first      int
+		count      int
+	}{
+		{"full", 48000, nil, nil, 0, 128},
+		{"nyquist", 16000, nil, nil, 0, 120},
+		{"range", 48000, intPtr(21), intPtr(108), 21, 88},
+		{"min_nyquist", 16000, intPtr(60), nil, 60, 60},
+		{"single", 48000, intPtr(69), intPtr(69), 69, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			first, freqs, err := midiNotes(&filtersConfig{SampleRate: tt.sampleRate, NoteMin: tt.noteMin, NoteMax: tt.noteMax})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first != tt.first || len(freqs) != tt.count {
+				t.Fatalf("expected %d notes from %d, got %d from %d", tt.count, tt.first, len(freqs), first)
+			}
+			if i := 69 - first; i >= 0 && i < len(freqs) && freqs[i] != 440 {
+				t.Errorf("expected 440 Hz for A4, got %g", freqs[i])
+			}
+			if f := freqs[len(freqs)-1]; f >= tt.sampleRate/2 {
+				t.Errorf("last note above the nyquist frequency: %g", f)
+			}
+		})
+	}
+
+	a4 := 432.
+	_, freqs, err := midiNotes(&filtersConfig{SampleRate: 48000, A4Frequency: &a4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(freqs[81]-864) > 1e-9 {
+		t.Errorf("expected 864 Hz for A5, got %g", freqs[81])
+	}
+
+	for _, tt := range []struct {
+		name    string
+		noteMin int
+		noteMax int
+		err     string
+	}{
+		{"nyquist", 0, 127, "filters: midi note 120 frequency (8372.02 Hz) must be below the nyquist frequency (8000.00 Hz), reduce note_max"},
+		{"inverted", 60, 59, "filters: note_min and note_max must be >= 0, <= 127, and note_min must be <= note_max"},
+		{"negative", -1, 60, "filters: note_min and note_max must be >= 0, <= 127, and note_min must be <= note_max"},
+		{"above", 0, 128, "filters: note_min and note_max must be >= 0, <= 127, and note_min must be <= note_max"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := midiNotes(&filtersConfig{SampleRate: 16000, NoteMin: &tt.noteMin, NoteMax: &tt.noteMax})
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package filters
+
+import (
+	"math"
+)
+
+type filterSvfTpt struct {
+	G  float64
+	K  float64
+	A1 float64
+	A2 float64
+	A3 float64
+}
+
+type filterLadderTpt struct {
+	G float64
+	K float64
+	A float64
+}
+
+const (
+	ladderFeedbackMax = 4.0
+)
+
+// chamberlinF returns the frequency coefficient of the Chamberlin state variable filter:
+// hp = x - lp - q * bp; bp += f * hp; lp += f * bp
+func chamberlinF(nfreq float64) float64 {
+	return 2 * math.Sin(math.Pi*nfreq)
+}
+
+// svfTptCoefficients returns the precomputed terms of the topology-preserving transform
+// (zero-delay feedback) state variable filter:
+// v3 = x - ic2eq; v1 = a1 * ic1eq + a2 * v3; v2 = ic2eq + a2 * ic1eq + a3 * v3
+func svfTptCoefficients(nfreq float64, q float64) filterSvfTpt {
+	g := math.Tan(math.Pi * nfreq)
+	k := 1 / q
+	a1 := 1 / (1 + g*(g+k))
+	return filterSvfTpt{
+		G:  g,
+		K:  k,
+		A1: a1,
+		A2: g * a1,
+		A3: g * g * a1,
+	}
+}
+
+// ladderTptCoefficients returns the precomputed terms of the topology-preserving transform
+// (zero-delay feedback) 4-pole ladder filter, where g is the one-pole integrator gain
+// g / (1 + g), and a is the feedback loop resolution term 1 / (1 + k * g^4).
+func ladderTptCoefficients(nfreq float64, k float64) filterLadderTpt {
+	g := math.Tan(math.Pi * nfreq)
+	gg := g / (1 + g)
+	return filterLadderTpt{
+		G: gg,
+		K: k,
+		A: 1 / (1 + k*gg*gg*gg*gg),
+	}
+}
+
+// filterDirectForm4 is the direct form equivalent of a 4th order filter, used to plot the
+// response of filters that aren't implemented in direct form.
+type filterDirectForm4 struct {
+	B0 float64 `filter:"b0"`
+	B1 float64 `filter:"b1"`
+	B2 float64 `filter:"b2"`
+	B3 float64 `filter:"b3"`
+	B4 float64 `filter:"b4"`
+	A1 float64 `filter:"a1"`
+	A2 float64 `filter:"a2"`
+	A3 float64 `filter:"a3"`
+	A4 float64 `filter:"a4"`
+}
+
+// chamberlinResponse returns the direct form equivalent of the low-pass output of the
+// Chamberlin state variable filter:
+// H(z) = f^2 / (1 - (2 - f^2 - f * q) * z^-1 + (1 - f * q) * z^-2)
+func chamberlinResponse(c coefficientSet) filterBiquad {
+	return filterBiquad{
+		B0: c["f"] * c["f"],
+		A1: 2 - c["f"]*c["f"] - c["f"]*c["q"],
+		A2: -(1 - c["f"]*c["q"]),
+	}
+}
+
+// svfTptResponse returns the direct form equivalent of the low-pass output of the TPT
+// state variable filter, from its state space representation:
+// ic1eq' = (2 * a1 - 1) * ic1eq - 2 * a2 * ic2eq + 2 * a2 * x
+// ic2eq' = 2 * a2 * ic1eq + (1 - 2 * a3) * ic2eq + 2 * a3 * x
+// lp = a2 * ic1eq + (1 - a3) * ic2eq + a3 * x
+func svfTptResponse(c coefficientSet) filterBiquad {
+	a11, a12, a21, a22 := 2*c["a1"]-1, -2*c["a2"], 2*c["a2"], 1-2*c["a3"]
+	b1, b2 := 2*c["a2"], 2*c["a3"]
+	c1, c2 := c["a2"], 1-c["a3"]
+	d := c["a3"]
+
+	tr := a11 + a22
+	det := a11*a22 - a12*a21
+	n1 := c1*b1 + c2*b2
+	n0 := c1*(a12*b2-a22*b1) + c2*(a21*b1-a11*b2)
+	return filterBiquad{
+		B0: d,
+		B1: n1 - d*tr,
+		B2: n0 + d*det,
+		A1: tr,
+		A2: -det,
+	}
+}
+
+// ladderTptResponse returns the direct form equivalent of the TPT 4-pole ladder filter,
+// H(z) = H1(z)^4 / (1 + k * H1(z)^4), where each stage has the response
+// H1(z) = g * (1 + z^-1) / (1 - (1 - 2 * g) * z^-1). the polynomials are multiplied by
+// a = 1 / (1 + k * g^4), so that the leading denominator coefficient is 1.
+func ladderTptResponse(c coefficientSet) filterDirectForm4 {
+	binomial := []float64{1, 4, 6, 4, 1}
+	g4 := math.Pow(c["g"], 4)
+	p := 1 - 2*c["g"]
+
+	b := make([]float64, 0, len(binomial))
+	a := make([]float64, 0, len(binomial))
+	for i, n := range binomial {
+		b = append(b, c["a"]*g4*n)
+		a = append(a, -c["a"]*(n*math.Pow(-p, float64(i))+c["k"]*g4*n))
+	}
+	return filterDirectForm4{
+		B0: b[0],
+		B1: b[1],
+		B2: b[2],
+		B3: b[3],
+		B4: b[4],
+		A1: a[1],
+		A2: a[2],
+		A3: a[3],
+		A4: a[4],
+	}
+}
+
+// responses returns the direct form equivalents of coefficient sets, for plotting.
+func responses[T any](sets [][]coefficientSet, response func(coefficientSet) T) []any {
+	rv := make([]any, 0, len(sets))
+	for _, row := range sets {
+		r := make([]T, 0, len(row))
+		for _, c := range row {
+			r = append(r, response(c))
+		}
+		rv = append(rv, r)
+	}
+	return rv
+}
@@ -0,0 +1,89 @@
+package filters
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// directForm returns the response of a direct form filter at the normalized frequency.
+func directForm(b []float64, a []float64, nfreq float64) complex128 {
+	num := complex(0, 0)
+	for i, v := range b {
+		num += complex(v, 0) * cmplx.Exp(complex(0, -2*math.Pi*nfreq*float64(i)))
+	}
+	den := complex(1, 0)
+	for i, v := range a {
+		den -= complex(v, 0) * cmplx.Exp(complex(0, -2*math.Pi*nfreq*float64(i+1)))
+	}
+	return num / den
+}
+
+func TestChamberlinResponse(t *testing.T) {
+	f, q := chamberlinF(0.01), 1/0.9
+	c := chamberlinResponse(coefficientSet{"f": f, "q": q})
+
+	// compare the impulse response of the filter with the direct form equivalent.
+	lp, bp := 0., 0.
+	x1, x2, y1, y2 := 0., 0., 0., 0.
+	for n := range 256 {
+		x := 0.
+		if n == 0 {
+			x = 1
+		}
+		hp := x - lp - q*bp
+		bp += f * hp
+		lp += f * bp
+
+		y := c.B0*x + c.B1*x1 + c.B2*x2 + c.A1*y1 + c.A2*y2
+		x2, x1, y2, y1 = x1, x, y1, y
+		if math.Abs(y-lp) > 1e-12 {
+			t.Fatalf("sample %d: expected %g, got %g", n, lp, y)
+		}
+	}
+}
+
+func TestSvfTptResponse(t *testing.T) {
+	for _, nfreq := range []float64{0.001, 0.01, 0.1, 0.3} {
+		for _, q := range []float64{0.5, 0.707, 4} {
+			s := svfTptCoefficients(nfreq, q)
+			c := svfTptResponse(coefficientSet{"g": s.G, "k": s.K, "a1": s.A1, "a2": s.A2, "a3": s.A3})
+
+			for _, f := range []float64{0.0001, nfreq, 0.2, 0.45} {
+				// bilinear transform of the analog low-pass 1 / (s^2 + k * s + 1).
+				z1 := cmplx.Exp(complex(0, -2*math.Pi*f))
+				g := complex(s.G, 0)
+				expected := g * g * (1 + z1) * (1 + z1) /
+					((1-z1)*(1-z1) + g*complex(s.K, 0)*(1-z1)*(1+z1) + g*g*(1+z1)*(1+z1))
+
+				got := directForm([]float64{c.B0, c.B1, c.B2}, []float64{c.A1, c.A2}, f)
+				if cmplx.Abs(got-expected) > 1e-9 {
+					t.Errorf("nfreq %g, q %g, f %g: expected %v, got %v", nfreq, q, f, expected, got)
+				}
+			}
+		}
+	}
+}
+
+func TestLadderTptResponse(t *testing.T) {
+	for _, nfreq := range []float64{0.001, 0.01, 0.1, 0.3} {
+		for _, k := range []float64{0, 1, 3.9} {
+			l := ladderTptCoefficients(nfreq, k)
+			c := ladderTptResponse(coefficientSet{"g": l.G, "k": l.K, "a": l.A})
+
+			for _, f := range []float64{0.0001, nfreq, 0.2, 0.45} {
+				z1 := cmplx.Exp(complex(0, -2*math.Pi*f))
+				h1 := complex(l.G, 0) * (1 + z1) / (1 - complex(1-2*l.G, 0)*z1)
+				h4 := h1 * h1 * h1 * h1
+				expected := h4 / (1 + complex(k, 0)*h4)
+
+				// the expanded 4th order polynomials lose some precision when the poles
+				// get close to z = 1.
+				got := directForm([]float64{c.B0, c.B1, c.B2, c.B3, c.B4}, []float64{c.A1, c.A2, c.A3, c.A4}, f)
+				if cmplx.Abs(got-expected) > 1e-5*cmplx.Abs(expected) {
+					t.Errorf("nfreq %g, k %g, f %g: expected %v, got %v", nfreq, k, f, expected, got)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package fir
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"rafaelmartins.com/p/synth-datagen/internal/convert"
+	"rafaelmartins.com/p/synth-datagen/internal/datareg"
+	"rafaelmartins.com/p/synth-datagen/internal/quantization"
+	"rafaelmartins.com/p/synth-datagen/internal/renderer"
+	"rafaelmartins.com/p/synth-datagen/internal/selector"
+)
+
+type FIR struct{}
+
+type firConfig struct {
+	SampleRate                     float64
+	Taps                           int
+	DataAttributes                 []string
+	Window                         *string
+	KaiserBeta                     *float64
+	Cutoff                         *float64 `selectors:"lowpass,highpass,lowpass_polyphase,highpass_polyphase"`
+	CutoffLow                      *float64 `selectors:"bandpass,bandpass_polyphase"`
+	CutoffHigh                     *float64 `selectors:"bandpass,bandpass_polyphase"`
+	PolyphaseFactor                *int     `selectors:"lowpass_polyphase,highpass_polyphase,bandpass_polyphase,halfband_polyphase"`
+	CoefficientsScalarType         string
+	CoefficientsFractionalBitWidth *uint8
+	QuantizationReport             *string
+	Rounding                       *string
+	Overflow                       *string
+}
+
+func (*FIR) GetName() string {
+	return "fir"
+}
+
+func (*FIR) GetAllowedSelectors() []string {
+	return []string{
+		"lowpass",
+		"highpass",
+		"bandpass",
+		"halfband",
+		"lowpass_polyphase",
+		"highpass_polyphase",
+		"bandpass_polyphase",
+		"halfband_polyphase",
+	}
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+func lowpass(nfreq float64, win []float64) []float64 {
+	m := float64(len(win)-1) / 2
+	rv := make([]float64, 0, len(win))
+	for i, w := range win {
+		rv = append(rv, 2*nfreq*sinc(2*nfreq*(float64(i)-m))*w)
+	}
+	return rv
+}
+
+func normalize(h []float64, nfreq float64) []float64 {
+	resp := complex(0, 0)
+	for i, v := range h {
+		resp += complex(v, 0) * cmplx.Exp(complex(0, -2*math.Pi*nfreq*float64(i)))
+	}
+
+	g := cmplx.Abs(resp)
+	if g == 0 {
+		return h
+	}
+
+	for i := range h {
+		h[i] /= g
+	}
+	return h
+}
+
+func (f *FIR) design(typ string, config *firConfig, win []float64) ([]float64, error) {
+	nyquist := config.SampleRate / 2
+
+	switch typ {
+	case "lowpass":
+		if *config.Cutoff <= 0 || *config.Cutoff >= nyquist {
+			return nil, fmt.Errorf("fir: cutoff must be > 0 and < %g", nyquist)
+		}
+		return normalize(lowpass(*config.Cutoff/config.SampleRate, win), 0), nil
+
+	case "highpass":
+		if *config.Cutoff <= 0 || *config.Cutoff >= nyquist {
+			return nil, fmt.Errorf("fir: cutoff must be > 0 and < %g", nyquist)
+		}
+		if config.Taps%2 == 0 {
+			return nil, errors.New("fir: highpass filters require an odd number of taps")
+		}
+
+		// spectral inversion of the lowpass filter
+		h := normalize(lowpass(*config.Cutoff/config.SampleRate, win), 0)
+		for i := range h {
+			h[i] = -h[i]
+		}
+		h[len(h)/2] += 1
+		return normalize(h, 0.5), nil
+
+	case "bandpass":
+		if *config.CutoffLow <= 0 || *config.CutoffHigh >= nyquist || *config.CutoffLow >= *config.CutoffHigh {
+			return nil, fmt.Errorf("fir: cutoff_low and cutoff_high must be > 0, < %g, and cutoff_low must be < cutoff_high", nyquist)
+		}
+
+		low := lowpass(*config.CutoffLow/config.SampleRate, win)
+		h := lowpass(*config.CutoffHigh/config.SampleRate, win)
+		for i := range h {
+			h[i] -= low[i]
+		}
+		return normalize(h, (*config.CutoffLow+*config.CutoffHigh)/(2*config.SampleRate)), nil
+
+	case "halfband":
+		if config.Taps%4 != 3 {
+			return nil, errors.New("fir: halfband filters require a number of taps equal to 4 * k + 3")
+		}
+
+		h := lowpass(0.25, win)
+
+		// every other coefficient is zero, except for the center one, that is 0.5. force
+		// them to get rid of rounding errors, and scale the remaining coefficients to sum
+		// 0.5, for unity gain at DC.
+		mid := len(h) / 2
+		sum := 0.
+		for i := range h {
+			if (i-mid)%2 == 0 {
+				h[i] = 0
+			} else {
+				sum += h[i]
+			}
+		}
+		for i := range h {
+			h[i] *= 0.5 / sum
+		}
+		h[mid] = 0.5
+		return h, nil
+	}
+
+	return nil, fmt.Errorf("fir: invalid filter type: %s", typ)
+}
+
+func polyphase(h []float64, factor int) [][]float64 {
+	cols := (len(h) + factor - 1) / factor
+	rv := make([][]float64, 0, factor)
+	for p := 0; p < factor; p++ {
+		row := make([]float64, cols)
+		for i := range cols {
+			if idx := p + i*factor; idx < len(h) {
+				row[i] = h[idx]
+			}
+		}
+		rv = append(rv, row)
+	}
+	return rv
+}
+
+func (f *FIR) Render(r renderer.Renderer, identifier string, dreg *datareg.DataReg, pmt map[string]any, slt *selector.Selector) error {
+	config := firConfig{}
+	if err := dreg.Evaluate(f.GetName(), &config, pmt, slt); err != nil {
+		return err
+	}
+
+	copts, err := convert.NewOptions(config.Rounding, config.Overflow)
+	if err != nil {
+		return err
+	}
+
+	if config.Taps < 1 {
+		return errors.New("fir: taps must be >= 1")
+	}
+
+	wname := "hamming"
+	if config.Window != nil {
+		wname = *config.Window
+	}
+
+	beta := kaiserBeta
+	if config.KaiserBeta != nil {
+		beta = *config.KaiserBeta
+	}
+
+	win, err := window(wname, beta, config.Taps)
+	if err != nil {
+		return err
+	}
+
+	copts = copts.WithFractionalBitWidth(config.CoefficientsFractionalBitWidth)
+
+	for _, typ := range []string{"lowpass", "highpass", "bandpass", "halfband"} {
+		if !slt.IsSelected(typ) && !slt.IsSelected(typ+"_polyphase") {
+			continue
+		}
+
+		h, err := f.design(typ, &config, win)
+		if err != nil {
+			return err
+		}
+
+		if slt.IsSelected(typ) {
+			v, err := convert.Slice(h, config.CoefficientsScalarType, copts.WithIdentifier(identifier+"_"+typ+"_coefficients"))
+			if err != nil {
+				return err
+			}
+			r.AddData(identifier+"_"+typ+"_coefficients", renderer.Typed{Type: config.CoefficientsScalarType, Value: v}, config.DataAttributes, nil)
+			if err := quantization.Report(r, config.QuantizationReport, identifier+"_"+typ+"_coefficients", config.CoefficientsScalarType, copts, h, v); err != nil {
+				return err
+			}
+		}
+
+		if slt.IsSelected(typ + "_polyphase") {
+			if *config.PolyphaseFactor < 1 || *config.PolyphaseFactor > config.Taps {
+				return fmt.Errorf("fir: polyphase_factor must be >= 1 and <= %d", config.Taps)
+			}
+
+			phases := polyphase(h, *config.PolyphaseFactor)
+			rv := make([]any, 0, *config.PolyphaseFactor)
+			for j, phase := range phases {
+				v, err := convert.Slice(phase, config.CoefficientsScalarType, copts.WithIdentifier(fmt.Sprintf("%s_%s_polyphase_coefficients[%d]", identifier, typ, j)))
+				if err != nil {
+					return err
+				}
+				rv = append(rv, v)
+			}
+			r.AddData(identifier+"_"+typ+"_polyphase_coefficients", renderer.Typed{Type: config.CoefficientsScalarType, Value: rv}, config.DataAttributes, nil)
+			if err := quantization.Report(r, config.QuantizationReport, identifier+"_"+typ+"_polyphase_coefficients", config.CoefficientsScalarType, copts, phases, rv); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
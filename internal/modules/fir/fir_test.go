@@ -0,0 +1,164 @@
+package fir
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// response returns the magnitude of the response of the filter at the normalized frequency.
+func response(h []float64, nfreq float64) float64 {
+	rv := complex(0, 0)
+	for i, v := range h {
+		rv += complex(v, 0) * cmplx.Exp(complex(0, -2*math.Pi*nfreq*float64(i)))
+	}
+	return cmplx.Abs(rv)
+}
+
+func newTestConfig(taps int) *firConfig {
+	cutoff := 4000.
+	low := 2000.
+	high := 6000.
+	return &firConfig{
+		SampleRate: 48000,
+		Taps:       taps,
+		Cutoff:     &cutoff,
+		CutoffLow:  &low,
+		CutoffHigh: &high,
+	}
+}
+
+func design(t *testing.T, typ string, config *firConfig) []float64 {
+	t.Helper()
+	win, err := window("hamming", 0, config.Taps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := (&FIR{}).design(typ, config, win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != config.Taps {
+		t.Fatalf("%s: unexpected number of taps: %d", typ, len(h))
+	}
+	return h
+}
+
+func TestDesignGain(t *testing.T) {
+	config := newTestConfig(31)
+
+	lp := design(t, "lowpass", config)
+	if g := response(lp, 0); math.Abs(g-1) > 1e-12 {
+		t.Errorf("lowpass: expected unity gain at dc, got %g", g)
+	}
+	if g := response(lp, 0.5); g > 0.01 {
+		t.Errorf("lowpass: expected attenuation at nyquist, got %g", g)
+	}
+
+	hp := design(t, "highpass", config)
+	if g := response(hp, 0.5); math.Abs(g-1) > 1e-12 {
+		t.Errorf("highpass: expected unity gain at nyquist, got %g", g)
+	}
+	if g := response(hp, 0); g > 1e-12 {
+		t.Errorf("highpass: expected zero gain at dc, got %g", g)
+	}
+
+	bp := design(t, "bandpass", config)
+	if g := response(bp, 4000./48000); math.Abs(g-1) > 1e-12 {
+		t.Errorf("bandpass: expected unity gain at the center frequency, got %g", g)
+	}
+	if g := response(bp, 0); g > 0.1 {
+		t.Errorf("bandpass: expected attenuation at dc, got %g", g)
+	}
+}
+
+func TestDesignHalfband(t *testing.T) {
+	for _, taps := range []int{7, 11, 31} {
+		h := design(t, "halfband", newTestConfig(taps))
+
+		mid := taps / 2
+		if h[mid] != 0.5 {
+			t.Errorf("%d taps: expected center tap 0.5, got %g", taps, h[mid])
+		}
+		for i, v := range h {
+			if i != mid && (i-mid)%2 == 0 && v != 0 {
+				t.Errorf("%d taps: expected zero tap at %d, got %g", taps, i, v)
+			}
+		}
+		if h[0] == 0 || h[taps-1] == 0 {
+			t.Errorf("%d taps: unexpected zero end taps: %v", taps, h)
+		}
+		if g := response(h, 0); math.Abs(g-1) > 1e-12 {
+			t.Errorf("%d taps: expected unity gain at dc, got %g", taps, g)
+		}
+		if g := response(h, 0.25); math.Abs(g-0.5) > 1e-12 {
+			t.Errorf("%d taps: expected half gain at a quarter of the sample rate, got %g", taps, g)
+		}
+	}
+}
+
+func TestPolyphase(t *testing.T) {
+	h := design(t, "lowpass", newTestConfig(31))
+
+	for _, factor := range []int{1, 2, 3, 4, 31} {
+		phases := polyphase(h, factor)
+		if len(phases) != factor {
+			t.Fatalf("factor %d: unexpected number of phases: %d", factor, len(phases))
+		}
+
+		cols := (len(h) + factor - 1) / factor
+		for p, phase := range phases {
+			if len(phase) != cols {
+				t.Fatalf("factor %d: phase %d: unexpected length: %d", factor, p, len(phase))
+			}
+		}
+
+		for i := range cols * factor {
+			expected := 0.
+			if i < len(h) {
+				expected = h[i]
+			}
+			if got := phases[i%factor][i/factor]; got != expected {
+				t.Errorf("factor %d: tap %d: expected %g, got %g", factor, i, expected, got)
+			}
+		}
+	}
+}
+
+func TestDesignErrors(t *testing.T) {
+	nyquist := 24000.
+	low := 2000.
+	for _, tt := range []struct {
+		name   string
+		typ    string
+		taps   int
+		config func(c *firConfig)
+		err    string
+	}{
+		{"lowpass_nyquist", "lowpass", 31, func(c *firConfig) { c.Cutoff = &nyquist }, "fir: cutoff must be > 0 and < 24000"},
+		{"highpass_nyquist", "highpass", 31, func(c *firConfig) { c.Cutoff = &nyquist }, "fir: cutoff must be > 0 and < 24000"},
+		{"highpass_even", "highpass", 30, func(c *firConfig) {}, "fir: highpass filters require an odd number of taps"},
+		{"bandpass_nyquist", "bandpass", 31, func(c *firConfig) { c.CutoffHigh = &nyquist }, "fir: cutoff_low and cutoff_high must be > 0, < 24000, and cutoff_low must be < cutoff_high"},
+		{"bandpass_inverted", "bandpass", 31, func(c *firConfig) { c.CutoffHigh = &low; c.CutoffLow = &nyquist }, "fir: cutoff_low and cutoff_high must be > 0, < 24000, and cutoff_low must be < cutoff_high"},
+		{"halfband_even", "halfband", 30, func(c *firConfig) {}, "fir: halfband filters require a number of taps equal to 4 * k + 3"},
+		{"halfband_4k+1", "halfband", 29, func(c *firConfig) {}, "fir: halfband filters require a number of taps equal to 4 * k + 3"},
+		{"invalid", "allpass", 31, func(c *firConfig) {}, "fir: invalid filter type: allpass"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newTestConfig(tt.taps)
+			tt.config(config)
+			win, err := window("hamming", 0, tt.taps)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := (&FIR{}).design(tt.typ, config, win); err == nil || err.Error() != tt.err {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	// an even number of taps is valid for lowpass and bandpass filters.
+	for _, typ := range []string{"lowpass", "bandpass"} {
+		design(t, typ, newTestConfig(30))
+	}
+}
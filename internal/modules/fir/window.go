@@ -0,0 +1,54 @@
+package fir
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	kaiserBeta = 8.6
+)
+
+// besselI0 computes the zeroth order modified Bessel function of the first kind,
+// using its power series.
+func besselI0(x float64) float64 {
+	rv := 1.
+	term := 1.
+	for k := 1.; k < 100; k++ {
+		term *= (x / (2 * k)) * (x / (2 * k))
+		rv += term
+		if term < rv*1e-17 {
+			break
+		}
+	}
+	return rv
+}
+
+func window(name string, beta float64, taps int) ([]float64, error) {
+	rv := make([]float64, 0, taps)
+	if taps == 1 {
+		return append(rv, 1), nil
+	}
+
+	m := float64(taps - 1)
+	for i := 0.; i < float64(taps); i++ {
+		switch name {
+		case "hann":
+			rv = append(rv, 0.5-0.5*math.Cos(2*math.Pi*i/m))
+
+		case "hamming":
+			rv = append(rv, 0.54-0.46*math.Cos(2*math.Pi*i/m))
+
+		case "blackman":
+			rv = append(rv, 0.42-0.5*math.Cos(2*math.Pi*i/m)+0.08*math.Cos(4*math.Pi*i/m))
+
+		case "kaiser":
+			r := 2*i/m - 1
+			rv = append(rv, besselI0(beta*math.Sqrt(1-r*r))/besselI0(beta))
+
+		default:
+			return nil, fmt.Errorf("fir: invalid window: %s", name)
+		}
+	}
+	return rv, nil
+}
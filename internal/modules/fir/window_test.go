@@ -0,0 +1,70 @@
+package fir
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBesselI0(t *testing.T) {
+	for _, tt := range []struct {
+		x        float64
+		expected float64
+	}{
+		{0, 1},
+		{1, 1.2660658777520082},
+		{5, 27.239871823604442},
+		{8.6, 750.4611595631663},
+	} {
+		if got := besselI0(tt.x); math.Abs(got-tt.expected) > tt.expected*1e-12 {
+			t.Errorf("I0(%g): expected %.16g, got %.16g", tt.x, tt.expected, got)
+		}
+	}
+}
+
+func TestWindow(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		beta     float64
+		endpoint float64
+	}{
+		{"hann", 0, 0},
+		{"hamming", 0, 0.08},
+		{"blackman", 0, 0},
+		{"kaiser", 5, 1 / 27.239871823604442},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := window(tt.name, tt.beta, 31)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(w) != 31 {
+				t.Fatalf("unexpected length: %d", len(w))
+			}
+			for _, i := range []int{0, 30} {
+				if math.Abs(w[i]-tt.endpoint) > 1e-12 {
+					t.Errorf("w[%d]: expected %g, got %g", i, tt.endpoint, w[i])
+				}
+			}
+			if math.Abs(w[15]-1) > 1e-12 {
+				t.Errorf("center: expected 1, got %g", w[15])
+			}
+			for i := range w {
+				if math.Abs(w[i]-w[30-i]) > 1e-12 {
+					t.Errorf("w[%d]: not symmetric: %g != %g", i, w[i], w[30-i])
+				}
+			}
+		})
+	}
+
+	w, err := window("hann", 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(w) != 1 || w[0] != 1 {
+		t.Errorf("unexpected single tap window: %v", w)
+	}
+
+	if _, err := window("bartlett", 0, 31); err == nil || err.Error() != "fir: invalid window: bartlett" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"rafaelmartins.com/p/synth-datagen/internal/datareg"
 	"rafaelmartins.com/p/synth-datagen/internal/modules/adsr"
 	"rafaelmartins.com/p/synth-datagen/internal/modules/filters"
+	"rafaelmartins.com/p/synth-datagen/internal/modules/fir"
+	"rafaelmartins.com/p/synth-datagen/internal/modules/noise"
 	"rafaelmartins.com/p/synth-datagen/internal/modules/notes"
 	"rafaelmartins.com/p/synth-datagen/internal/modules/wavetables"
 	"rafaelmartins.com/p/synth-datagen/internal/renderer"
@@ -25,13 +27,15 @@ var (
 		&wavetables.Wavetables{},
 		&filters.Filters{},
 		&notes.Notes{},
+		&fir.FIR{},
+		&noise.Noise{},
 	}
 
 	dreg = &datareg.DataReg{}
 )
 
-func SetGlobalParameters(pmt map[string]any) {
-	dreg = datareg.New(pmt)
+func SetGlobalParameters(pmt map[string]any, dir string) {
+	dreg = datareg.New(pmt, dir)
 }
 
 func Render(r renderer.Renderer, identifier string, module string, pmt map[string]any, sel []string) error {
@@ -0,0 +1,261 @@
+package noise
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"rafaelmartins.com/p/synth-datagen/internal/convert"
+	"rafaelmartins.com/p/synth-datagen/internal/datareg"
+	"rafaelmartins.com/p/synth-datagen/internal/quantization"
+	"rafaelmartins.com/p/synth-datagen/internal/renderer"
+	"rafaelmartins.com/p/synth-datagen/internal/selector"
+)
+
+const defaultSeed = 1
+
+type Noise struct{}
+
+type noiseConfig struct {
+	Samples            int
+	SampleAmplitude    float64
+	SampleScalarType   string
+	DataAttributes     []string
+	Seed               *uint64
+	SampleHoldLength   *int
+	Unipolar           *bool
+	SampleOffset       *float64
+	QuantizationReport *string
+	Rounding           *string
+	Overflow           *string
+}
+
+func (*Noise) GetName() string {
+	return "noise"
+}
+
+func (*Noise) GetAllowedSelectors() []string {
+	return []string{"white", "pink", "brown", "sample_hold"}
+}
+
+// newRand returns the random number generator of a selector. each selector has its own
+// stream, so that the tables don't change when other selectors are added or removed.
+func (n *Noise) newRand(seed uint64, sel string) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, uint64(slices.Index(n.GetAllowedSelectors(), sel))))
+}
+
+func white(rng *rand.Rand, samples int) []float64 {
+	rv := make([]float64, 0, samples)
+	for range samples {
+		rv = append(rv, 2*rng.Float64()-1)
+	}
+	return rv
+}
+
+// onePole filters the table with y[i] = a * y[i-1] + g * x[i], starting from the steady
+// state of the table played in a loop, so that the output loops without discontinuities.
+func onePole(x []float64, a float64, g float64) []float64 {
+	y := 0.
+	for i, v := range x {
+		y += math.Pow(a, float64(len(x)-1-i)) * v
+	}
+	y *= g / (1 - math.Pow(a, float64(len(x))))
+
+	rv := make([]float64, 0, len(x))
+	for _, v := range x {
+		y = a*y + g*v
+		rv = append(rv, y)
+	}
+	return rv
+}
+
+// pink filters white noise with Paul Kellet's refined pink noise filter, a sum of one-pole
+// low-pass filters that approximates a -3dB/octave slope within 0.05dB above 0.0002 times
+// the sample rate.
+func pink(rng *rand.Rand, samples int) []float64 {
+	w := white(rng, samples)
+
+	poles := []struct {
+		a float64
+		g float64
+	}{
+		{0.99886, 0.0555179},
+		{0.99332, 0.0750759},
+		{0.96900, 0.1538520},
+		{0.86650, 0.3104856},
+		{0.55000, 0.5329522},
+		{-0.7616, -0.0168980},
+	}
+
+	rv := make([]float64, samples)
+	for _, p := range poles {
+		for i, v := range onePole(w, p.a, p.g) {
+			rv[i] += v
+		}
+	}
+	for i, v := range w {
+		rv[i] += 0.5362*v + 0.115926*w[(i+samples-1)%samples]
+	}
+	return rv
+}
+
+// brown integrates zero mean white noise, for a -6dB/octave slope. removing the mean makes
+// the integral end where it started, so that the table loops without discontinuities.
+func brown(rng *rand.Rand, samples int) []float64 {
+	w := removeDC(white(rng, samples))
+
+	rv := make([]float64, 0, samples)
+	y := 0.
+	for _, v := range w {
+		y += v
+		rv = append(rv, y)
+	}
+	return rv
+}
+
+// sampleHold generates random values, each repeated for length samples.
+func sampleHold(rng *rand.Rand, samples int, length int) []float64 {
+	rv := make([]float64, 0, samples)
+	v := 0.
+	for i := range samples {
+		if i%length == 0 {
+			v = 2*rng.Float64() - 1
+		}
+		rv = append(rv, v)
+	}
+	return rv
+}
+
+func removeDC(data []float64) []float64 {
+	mean := 0.
+	for _, v := range data {
+		mean += v
+	}
+	if len(data) > 0 {
+		mean /= float64(len(data))
+	}
+
+	rv := make([]float64, 0, len(data))
+	for _, v := range data {
+		rv = append(rv, v-mean)
+	}
+	return rv
+}
+
+// normalize scales the table to the sample amplitude peak and adds the sample offset.
+func (n *Noise) normalize(config *noiseConfig, data []float64) []float64 {
+	peak := 0.
+	for _, v := range data {
+		peak = max(peak, math.Abs(v))
+	}
+
+	offset := n.sampleOffset(config)
+	rv := make([]float64, 0, len(data))
+	for _, v := range data {
+		if peak != 0 {
+			v *= config.SampleAmplitude / peak
+		}
+		rv = append(rv, v+offset)
+	}
+	return rv
+}
+
+func (n *Noise) sampleOffset(config *noiseConfig) float64 {
+	if config.SampleOffset != nil {
+		return *config.SampleOffset
+	}
+	if config.Unipolar != nil && *config.Unipolar {
+		return config.SampleAmplitude
+	}
+	return 0
+}
+
+// checkSampleOffset checks that the range of the offset tables fits the scalar type.
+func (n *Noise) checkSampleOffset(config *noiseConfig, copts *convert.Options) error {
+	if config.SampleOffset != nil && config.Unipolar != nil && *config.Unipolar {
+		return errors.New("noise: unipolar and sample_offset can't be used together")
+	}
+	if config.SampleOffset == nil && (config.Unipolar == nil || !*config.Unipolar) {
+		return nil
+	}
+
+	min, max, err := convert.Limits(config.SampleScalarType, copts)
+	if err != nil {
+		return err
+	}
+
+	offset := n.sampleOffset(config)
+	if offset-config.SampleAmplitude < min || offset+config.SampleAmplitude > max {
+		return fmt.Errorf("noise: sample range [%g, %g] doesn't fit %s range [%g, %g]",
+			offset-config.SampleAmplitude, offset+config.SampleAmplitude, config.SampleScalarType, min, max)
+	}
+	return nil
+}
+
+func (n *Noise) Render(r renderer.Renderer, identifier string, dreg *datareg.DataReg, pmt map[string]any, slt *selector.Selector) error {
+	config := noiseConfig{}
+	if err := dreg.Evaluate(n.GetName(), &config, pmt, slt); err != nil {
+		return err
+	}
+
+	if config.Samples <= 0 {
+		return errors.New("noise: samples must be > 0")
+	}
+
+	copts, err := convert.NewOptions(config.Rounding, config.Overflow)
+	if err != nil {
+		return err
+	}
+
+	if err := n.checkSampleOffset(&config, copts); err != nil {
+		return err
+	}
+
+	seed := uint64(defaultSeed)
+	if config.Seed != nil {
+		seed = *config.Seed
+	}
+
+	holdLength := 1
+	if config.SampleHoldLength != nil {
+		if *config.SampleHoldLength <= 0 {
+			return errors.New("noise: sample_hold_length must be > 0")
+		}
+		holdLength = *config.SampleHoldLength
+	}
+
+	for _, sel := range n.GetAllowedSelectors() {
+		if !slt.IsSelected(sel) {
+			continue
+		}
+
+		rng := n.newRand(seed, sel)
+
+		var data []float64
+		switch sel {
+		case "white":
+			data = removeDC(white(rng, config.Samples))
+		case "pink":
+			data = removeDC(pink(rng, config.Samples))
+		case "brown":
+			data = removeDC(brown(rng, config.Samples))
+		case "sample_hold":
+			data = sampleHold(rng, config.Samples, holdLength)
+		}
+		data = n.normalize(&config, data)
+
+		id := identifier + "_" + sel
+		d, err := convert.Slice(data, config.SampleScalarType, copts.WithIdentifier(id))
+		if err != nil {
+			return err
+		}
+		r.AddData(id, renderer.Typed{Type: config.SampleScalarType, Value: d}, config.DataAttributes, nil)
+		if err := quantization.Report(r, config.QuantizationReport, id, config.SampleScalarType, copts, data, d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package noise
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"rafaelmartins.com/p/synth-datagen/internal/fft"
+)
+
+// slope returns the spectral slope of the table in dB/octave, from a least squares fit of
+// the mean power of the octave bands between bins 2^lo and 2^hi.
+func slope(data []float64, lo int, hi int) float64 {
+	spec := fft.Forward(fft.Real(data))
+
+	xs := []float64{}
+	ys := []float64{}
+	for o := lo; o < hi; o++ {
+		p := 0.
+		for k := 1 << o; k < 1<<(o+1); k++ {
+			a := cmplx.Abs(spec[k])
+			p += a * a
+		}
+		xs = append(xs, float64(o))
+		ys = append(ys, 10*math.Log10(p/float64(int(1)<<o)))
+	}
+
+	mx, my := 0., 0.
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx /= float64(len(xs))
+	my /= float64(len(ys))
+
+	num, den := 0., 0.
+	for i := range xs {
+		num += (xs[i] - mx) * (ys[i] - my)
+		den += (xs[i] - mx) * (xs[i] - mx)
+	}
+	return num / den
+}
+
+func TestSpectralSlope(t *testing.T) {
+	tests := []struct {
+		name     string
+		gen      func(*rand.Rand, int) []float64
+		expected float64
+	}{
+		{"white", white, 0},
+		{"pink", pink, -10 * math.Log10(2)},
+		{"brown", brown, -20 * math.Log10(2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := slope(tt.gen(rand.New(rand.NewPCG(1, 2)), 1<<16), 5, 13)
+			if math.Abs(s-tt.expected) > 0.3 {
+				t.Errorf("expected slope %.2f dB/octave, got %.2f", tt.expected, s)
+			}
+		})
+	}
+}
+
+func TestLoop(t *testing.T) {
+	// the filter state at the start of the table must match the state at the end, as
+	// if the table was played in a loop.
+	w := white(rand.New(rand.NewPCG(1, 2)), 64)
+	y := onePole(w, 0.9, 0.5)
+	if v := 0.9*y[len(y)-1] + 0.5*w[0]; math.Abs(v-y[0]) > 1e-12 {
+		t.Errorf("expected %g, got %g", y[0], v)
+	}
+
+	b := brown(rand.New(rand.NewPCG(1, 2)), 64)
+	if math.Abs(b[len(b)-1]) > 1e-12 {
+		t.Errorf("brown noise doesn't loop: %g", b[len(b)-1])
+	}
+}
+
+func TestReproducible(t *testing.T) {
+	n := &Noise{}
+	for _, sel := range n.GetAllowedSelectors() {
+		t.Run(sel, func(t *testing.T) {
+			gen := func(seed uint64) []float64 {
+				rng := n.newRand(seed, sel)
+				switch sel {
+				case "white":
+					return white(rng, 256)
+				case "pink":
+					return pink(rng, 256)
+				case "brown":
+					return brown(rng, 256)
+				}
+				return sampleHold(rng, 256, 4)
+			}
+
+			if !slices.Equal(gen(1), gen(1)) {
+				t.Error("same seed generated different tables")
+			}
+			if slices.Equal(gen(1), gen(2)) {
+				t.Error("different seeds generated the same table")
+			}
+		})
+	}
+}
+
+func TestSampleHold(t *testing.T) {
+	data := sampleHold(rand.New(rand.NewPCG(1, 2)), 10, 4)
+	for i := range data {
+		if data[i] != data[i-i%4] {
+			t.Errorf("sample %d: expected %g, got %g", i, data[i-i%4], data[i])
+		}
+		if data[i] < -1 || data[i] >= 1 {
+			t.Errorf("sample %d out of range: %g", i, data[i])
+		}
+	}
+	if data[0] == data[4] || data[4] == data[8] {
+		t.Errorf("values not changed between steps: %v", data)
+	}
+}
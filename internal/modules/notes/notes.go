@@ -6,6 +6,7 @@ import (
 
 	"rafaelmartins.com/p/synth-datagen/internal/convert"
 	"rafaelmartins.com/p/synth-datagen/internal/datareg"
+	"rafaelmartins.com/p/synth-datagen/internal/quantization"
 	"rafaelmartins.com/p/synth-datagen/internal/renderer"
 	"rafaelmartins.com/p/synth-datagen/internal/selector"
 )
@@ -24,6 +25,9 @@ type notesConfig struct {
 	SamplesPerCycle              *int     `selectors:"phase_steps"`
 	PhaseStepsScalarType         *string  `selectors:"phase_steps"`
 	PhaseStepsFractionalBitWidth *uint8
+	QuantizationReport           *string
+	Rounding                     *string
+	Overflow                     *string
 }
 
 func (*Notes) GetName() string {
@@ -40,6 +44,11 @@ func (n *Notes) Render(r renderer.Renderer, identifier string, dreg *datareg.Dat
 		return err
 	}
 
+	copts, err := convert.NewOptions(config.Rounding, config.Overflow)
+	if err != nil {
+		return err
+	}
+
 	if config.A4Frequency == nil {
 		config.A4Frequency = new(float64)
 		*config.A4Frequency = a4Frequency
@@ -52,17 +61,15 @@ func (n *Notes) Render(r renderer.Renderer, identifier string, dreg *datareg.Dat
 			steps = append(steps, float64(*config.SamplesPerCycle)*freq / *config.SampleRate)
 		}
 
-		if config.PhaseStepsFractionalBitWidth != nil {
-			for idx := range steps {
-				steps[idx] *= float64(int(1) << *config.PhaseStepsFractionalBitWidth)
-			}
-		}
-
-		s, err := convert.Slice(steps, *config.PhaseStepsScalarType)
+		sopts := copts.WithFractionalBitWidth(config.PhaseStepsFractionalBitWidth)
+		s, err := convert.Slice(steps, *config.PhaseStepsScalarType, sopts.WithIdentifier(identifier+"_phase_steps"))
 		if err != nil {
 			return err
 		}
-		r.AddData(identifier+"_phase_steps", s, config.DataAttributes, nil)
+		r.AddData(identifier+"_phase_steps", renderer.Typed{Type: *config.PhaseStepsScalarType, Value: s}, config.DataAttributes, nil)
+		if err := quantization.Report(r, config.QuantizationReport, identifier+"_phase_steps", *config.PhaseStepsScalarType, sopts, steps, s); err != nil {
+			return err
+		}
 	}
 
 	if slt.IsSelected("names") {
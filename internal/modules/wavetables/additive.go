@@ -0,0 +1,27 @@
+package wavetables
+
+import (
+	"math"
+)
+
+// additive returns one cycle of the sum of the harmonics with the given amplitudes and phases
+// (in degrees), skipping harmonics above maxHarmonic.
+func additive(samples int, amplitudes []float64, phases []float64, maxHarmonic int) []float64 {
+	rv := make([]float64, samples)
+	for h := 1; h <= min(len(amplitudes), maxHarmonic); h++ {
+		amplitude := amplitudes[h-1]
+		if amplitude == 0 {
+			continue
+		}
+
+		phase := 0.
+		if h <= len(phases) {
+			phase = phases[h-1] * math.Pi / 180
+		}
+
+		for i := range rv {
+			rv[i] += amplitude * math.Sin(2*math.Pi*float64(h*i)/float64(samples)+phase)
+		}
+	}
+	return rv
+}
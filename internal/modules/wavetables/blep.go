@@ -0,0 +1,142 @@
+package wavetables
+
+import (
+	"math"
+	"math/cmplx"
+
+	"rafaelmartins.com/p/synth-datagen/internal/fft"
+)
+
+const (
+	blepOversampling  = 32
+	blepZeroCrossings = 16
+)
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blepImpulse returns a blackman windowed sinc with zeroCrossings zero crossings on each
+// side, sampled oversampling times per sample, and normalized to unit sum.
+func blepImpulse(oversampling int, zeroCrossings int) []float64 {
+	n := 2*zeroCrossings*oversampling + 1
+	rv := make([]float64, 0, n)
+	sum := 0.
+	for i := 0; i < n; i++ {
+		w := 0.42 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1)) + 0.08*math.Cos(4*math.Pi*float64(i)/float64(n-1))
+		v := w * sinc(float64(i-zeroCrossings*oversampling)/float64(oversampling))
+		rv = append(rv, v)
+		sum += v
+	}
+	for i := range rv {
+		rv[i] /= sum
+	}
+	return rv
+}
+
+// minimumPhase returns the minimum phase version of an impulse, using the real cepstrum.
+func minimumPhase(impulse []float64) []float64 {
+	// padding reduces the cepstral aliasing
+	n := 8 * fft.NextPowerOfTwo(len(impulse))
+	padded := make([]float64, n)
+	copy(padded, impulse)
+
+	spectrum := fft.Forward(fft.Real(padded))
+	for i, v := range spectrum {
+		spectrum[i] = complex(math.Log(max(cmplx.Abs(v), 1e-100)), 0)
+	}
+
+	cepstrum := fft.Inverse(spectrum)
+	folded := make([]complex128, n)
+	folded[0] = complex(real(cepstrum[0]), 0)
+	for i := 1; i < n/2; i++ {
+		folded[i] = complex(2*real(cepstrum[i]), 0)
+	}
+	folded[n/2] = complex(real(cepstrum[n/2]), 0)
+
+	spectrum = fft.Forward(folded)
+	for i, v := range spectrum {
+		spectrum[i] = cmplx.Exp(v)
+	}
+
+	mp := fft.Inverse(spectrum)
+	rv := make([]float64, 0, len(impulse))
+	for _, v := range mp[:len(impulse)] {
+		rv = append(rv, real(v))
+	}
+	return rv
+}
+
+// minBLEP returns the residual of a minimum phase band-limited unit step, starting at the
+// discontinuity, with 2 * zeroCrossings samples oversampled oversampling times.
+func minBLEP(oversampling int, zeroCrossings int) []float64 {
+	impulse := minimumPhase(blepImpulse(oversampling, zeroCrossings))
+
+	step := make([]float64, 0, len(impulse))
+	v := 0.
+	for _, s := range impulse {
+		v += s
+		step = append(step, v)
+	}
+
+	rv := make([]float64, 0, len(step)-1)
+	for _, s := range step[:len(step)-1] {
+		rv = append(rv, s/v-1)
+	}
+	return rv
+}
+
+// blamp returns the residual of a linear phase band-limited ramp with unit slope change
+// (per sample), centered at the discontinuity, with 2 * zeroCrossings samples oversampled
+// oversampling times.
+func blamp(oversampling int, zeroCrossings int) []float64 {
+	impulse := blepImpulse(oversampling, zeroCrossings)
+	center := zeroCrossings * oversampling
+
+	// integrate with the trapezoidal rule, so that both the band-limited step and the
+	// naive step are 0.5 at the discontinuity, and the step residual is odd.
+	step := make([]float64, 0, len(impulse))
+	v := 0.
+	for _, s := range impulse {
+		step = append(step, v+s/2)
+		v += s
+	}
+
+	residual := make([]float64, 0, len(step))
+	for i, s := range step {
+		switch {
+		case i < center:
+			residual = append(residual, s)
+		case i == center:
+			residual = append(residual, s-0.5)
+		default:
+			residual = append(residual, s-1)
+		}
+	}
+
+	rv := make([]float64, 0, len(residual)-1)
+	v = 0.
+	for _, s := range residual[:len(residual)-1] {
+		rv = append(rv, (v+s/2)/float64(oversampling))
+		v += s
+	}
+	return rv
+}
+
+// polyBLEP returns the 2 samples polynomial residual of a unit step, centered at the
+// discontinuity, oversampled oversampling times.
+func polyBLEP(oversampling int) []float64 {
+	rv := make([]float64, 0, 2*oversampling)
+	for i := 0; i < 2*oversampling; i++ {
+		t := float64(i)/float64(oversampling) - 1
+		if t < 0 {
+			rv = append(rv, (t+1)*(t+1)/2)
+		} else {
+			rv = append(rv, -(1-t)*(1-t)/2)
+		}
+	}
+	return rv
+}
@@ -0,0 +1,129 @@
+package wavetables
+
+import (
+	"fmt"
+	"reflect"
+
+	"rafaelmartins.com/p/synth-datagen/internal/convert"
+	"rafaelmartins.com/p/synth-datagen/internal/ctypes"
+	"rafaelmartins.com/p/synth-datagen/internal/renderer"
+)
+
+func (bl *Wavetables) guardSamples(config *wavetablesConfig) int {
+	if config.GuardSamples == nil {
+		return 0
+	}
+	return *config.GuardSamples
+}
+
+// guard appends n wrap-around guard samples to each cycle of a converted table.
+func guard(table any, n int) any {
+	if n == 0 {
+		return table
+	}
+
+	if t, ok := table.([]any); ok {
+		rv := make([]any, 0, len(t))
+		for _, c := range t {
+			rv = append(rv, guard(c, n))
+		}
+		return rv
+	}
+
+	val := reflect.ValueOf(table)
+	if val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Slice {
+		rv := reflect.MakeSlice(val.Type(), 0, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			rv = reflect.Append(rv, reflect.ValueOf(guard(val.Index(i).Interface(), n)))
+		}
+		return rv.Interface()
+	}
+
+	rv := reflect.MakeSlice(val.Type(), 0, val.Len()+n)
+	rv = reflect.AppendSlice(rv, val)
+	for i := 0; i < n; i++ {
+		rv = reflect.Append(rv, val.Index(i%val.Len()))
+	}
+	return rv.Interface()
+}
+
+// slopes returns the differences between consecutive samples of each cycle of a converted
+// table, x[i + 1] - x[i], wrapping around at the end of the cycle. The differences are
+// computed from the stored values and stored in the same C type.
+func slopes(table any, opts *convert.Options, identifier string) (any, error) {
+	if t, ok := table.([]any); ok {
+		rv := make([]any, 0, len(t))
+		for i, c := range t {
+			s, err := slopes(c, opts, fmt.Sprintf("%s[%d]", identifier, i))
+			if err != nil {
+				return nil, err
+			}
+			rv = append(rv, s)
+		}
+		return rv, nil
+	}
+
+	val := reflect.ValueOf(table)
+	if val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Slice {
+		rv := make([]any, 0, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			s, err := slopes(val.Index(i).Interface(), opts, fmt.Sprintf("%s[%d]", identifier, i))
+			if err != nil {
+				return nil, err
+			}
+			rv = append(rv, s)
+		}
+		return rv, nil
+	}
+
+	f64 := reflect.TypeOf(float64(0))
+	deltas := make([]float64, 0, val.Len())
+	for i := 0; i < val.Len(); i++ {
+		next := val.Index((i + 1) % val.Len()).Convert(f64).Float()
+		deltas = append(deltas, next-val.Index(i).Convert(f64).Float())
+	}
+
+	name, err := ctypes.FromType(val.Type().Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	// the delta across a discontinuity can be as large as the peak-to-peak amplitude of the
+	// cycle, twice the sample range. this is a configuration error, not something the
+	// overflow policy should silently saturate or wrap.
+	lo, hi, err := convert.Limits(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i, d := range deltas {
+		if d < lo || d > hi {
+			return nil, fmt.Errorf("wavetables: slopes: %s[%d]: delta %g is out of the %s range [%g, %g], reduce sample_amplitude", identifier, i, d, name, lo, hi)
+		}
+	}
+	return convert.Slice(deltas, name, opts.WithIdentifier(identifier))
+}
+
+// addCycles adds a converted table of cycles, with guard samples, and its slopes table,
+// if enabled.
+func (bl *Wavetables) addCycles(r renderer.Renderer, config *wavetablesConfig, copts *convert.Options, id string, table any) error {
+	n := bl.guardSamples(config)
+
+	r.AddData(id, renderer.Typed{Type: config.SampleScalarType, Value: guard(table, n)}, config.DataAttributes, nil)
+	if n > 0 {
+		renderer.AddPadding(r, id, n)
+	}
+
+	if config.Slopes == nil || !*config.Slopes {
+		return nil
+	}
+
+	s, err := slopes(table, copts, id+"_slopes")
+	if err != nil {
+		return err
+	}
+	r.AddData(id+"_slopes", renderer.Typed{Type: config.SampleScalarType, Value: guard(s, n)}, config.DataAttributes, nil)
+	if n > 0 {
+		renderer.AddPadding(r, id+"_slopes", n)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package wavetables
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"rafaelmartins.com/p/synth-datagen/internal/codegen"
+	"rafaelmartins.com/p/synth-datagen/internal/convert"
+)
+
+func TestGuard3D(t *testing.T) {
+	table := [][][]int16{}
+	for i := range 2 {
+		frames := [][]int16{}
+		for j := range 3 {
+			frames = append(frames, []int16{int16(10 * (3*i + j)), 1, 2, 3})
+		}
+		table = append(table, frames)
+	}
+
+	g, ok := guard(table, 2).([][][]int16)
+	if !ok {
+		t.Fatalf("unexpected guarded table type: %T", guard(table, 2))
+	}
+	if len(g) != 2 || len(g[0]) != 3 || len(g[0][0]) != 6 {
+		t.Fatalf("unexpected guarded table shape: %v", g)
+	}
+	for i := range g {
+		for j := range g[i] {
+			if c := g[i][j]; c[4] != c[0] || c[5] != c[1] {
+				t.Errorf("cycle [%d][%d]: unexpected guard samples: %v", i, j, c)
+			}
+		}
+	}
+
+	copts, err := convert.NewOptions(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 2
+	slopes := true
+	config := &wavetablesConfig{
+		GuardSamples: &n,
+		Slopes:       &slopes,
+	}
+
+	h := codegen.NewHeader()
+	if err := (&Wavetables{}).addCycles(h, config, copts, "osc", table); err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	if err := h.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	got := buf.String()
+	for _, id := range []string{"osc", "osc_slopes"} {
+		for _, expected := range []string{
+			"static const int16_t " + id + "[2][3][6] = {",
+			"#define " + id + "_len_0 2\n",
+			"#define " + id + "_len_1 3\n",
+			"#define " + id + "_len_2 4\n",
+			"#define " + id + "_padded_len_2 6\n",
+		} {
+			if !strings.Contains(got, expected) {
+				t.Errorf("expected %q in %q", expected, got)
+			}
+		}
+	}
+}
+
+func TestSlopesRange(t *testing.T) {
+	copts, err := convert.NewOptions(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := slopes([][]int16{{16000, 16000, -16000, -16000}}, copts, "osc_slopes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := s.([]any); !ok || len(v) != 1 {
+		t.Fatalf("unexpected slopes: %v", s)
+	} else if c, ok := v[0].([]int16); !ok || len(c) != 4 || c[1] != -32000 || c[3] != 32000 {
+		t.Errorf("unexpected slopes: %v", v[0])
+	}
+
+	_, err = slopes([][]int16{{32000, 32000, -32000, -32000}}, copts, "osc_slopes")
+	if err == nil || err.Error() != "wavetables: slopes: osc_slopes[0][1]: delta -64000 is out of the int16_t range [-32768, 32767], reduce sample_amplitude" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// the overflow policy doesn't change the check.
+	for _, overflow := range []string{"saturate", "wrap"} {
+		opts, err := convert.NewOptions(nil, &overflow)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := slopes([]int16{32000, -32000}, opts, "osc_slopes"); err == nil {
+			t.Errorf("%s: expected error", overflow)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package wavetables
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/bits"
+	"math/cmplx"
+	"slices"
+
+	"rafaelmartins.com/p/synth-datagen/internal/convert"
+	"rafaelmartins.com/p/synth-datagen/internal/fft"
+	"rafaelmartins.com/p/synth-datagen/internal/quantization"
+	"rafaelmartins.com/p/synth-datagen/internal/renderer"
+)
+
+const (
+	mipmapMinSamples = 16
+
+	// harmonics with magnitude below this fraction of the strongest harmonic are
+	// considered numerical noise, and not content of the row.
+	mipmapThreshold = 1e-9
+)
+
+var mipmapSelectors = []string{"blsquare", "bltriangle", "blsawtooth", "additive", "wav"}
+
+func (bl *Wavetables) mipmap(config *wavetablesConfig, sel string) bool {
+	return config.BandlimitedMipmap != nil && *config.BandlimitedMipmap && slices.Contains(mipmapSelectors, sel)
+}
+
+// mipmapUnsupported logs that a band-limited 3-D table is stored with full length rows,
+// even if bandlimited_mipmap is set.
+func (bl *Wavetables) mipmapUnsupported(config *wavetablesConfig, id string, sel string) {
+	if config.BandlimitedMipmap == nil || !*config.BandlimitedMipmap {
+		return
+	}
+	if sel == "blpulse" || (sel == "morph" && config.MorphBandlimited != nil && *config.MorphBandlimited) {
+		log.Printf("wavetables: %s: bandlimited_mipmap is not supported by %s, rows stored with full length", id, sel)
+	}
+}
+
+func (bl *Wavetables) mipmapMinSamples(config *wavetablesConfig) int {
+	if config.BandlimitedMipmapMinSamples != nil {
+		return *config.BandlimitedMipmapMinSamples
+	}
+	return mipmapMinSamples
+}
+
+// highestHarmonic returns the highest harmonic of a cycle with a magnitude above the
+// noise threshold, ignoring the dc offset.
+func highestHarmonic(data []float64) int {
+	spec := fft.Forward(fft.Real(data))
+	mags := make([]float64, 0, len(spec)/2+1)
+	peak := 0.
+	for k := 0; k <= len(spec)/2; k++ {
+		mag := 0.
+		if k > 0 {
+			mag = cmplx.Abs(spec[k])
+		}
+		mags = append(mags, mag)
+		peak = math.Max(peak, mag)
+	}
+
+	for k := len(mags) - 1; k > 0; k-- {
+		if mags[k] > peak*mipmapThreshold {
+			return k
+		}
+	}
+	return 0
+}
+
+// mipmapLength returns the number of samples of a mipmapped table row. samples_per_cycle
+// is halved while all the harmonics of the row still fit, down to the minimum number of
+// samples. the harmonics are limited by the nyquist frequency of the row, and by the
+// content actually present in the row (e.g. an additive table with a few harmonics).
+func (bl *Wavetables) mipmapLength(config *wavetablesConfig, row int, data []float64) int {
+	h := min(bl.maxHarmonic(config, row), highestHarmonic(data))
+	rv := len(data)
+	for rv%2 == 0 && rv/2 >= bl.mipmapMinSamples(config) && h <= (rv/2-1)/2 {
+		rv /= 2
+	}
+	return rv
+}
+
+// decimate keeps every n-th sample of a cycle. the cycle must not have harmonics at or
+// above half of the new length, so that no information is lost.
+func decimate(data []float64, length int) []float64 {
+	step := len(data) / length
+	rv := make([]float64, 0, length)
+	for i := 0; i < len(data); i += step {
+		rv = append(rv, data[i])
+	}
+	return rv
+}
+
+// addMipmap adds a band-limited table with rows of decreasing lengths, as a jagged array,
+// along with the phase shift of each row.
+func (bl *Wavetables) addMipmap(r renderer.Renderer, config *wavetablesConfig, copts *convert.Options, opts *convert.Options, id string, data [][]float64) error {
+	min := bl.mipmapMinSamples(config)
+	if min < 2 || min > config.SamplesPerCycle {
+		return fmt.Errorf("wavetables: bandlimited_mipmap_min_samples must be >= 2 and <= %d", config.SamplesPerCycle)
+	}
+
+	n := bl.guardSamples(config)
+	src := make([][]float64, 0, len(data))
+	rows := make([]any, 0, len(data))
+	grows := make([]any, 0, len(data))
+	slps := make([]any, 0, len(data))
+	shifts := make([]uint8, 0, len(data))
+	for i, row := range data {
+		length := bl.mipmapLength(config, i, row)
+		row = decimate(row, length)
+		src = append(src, row)
+
+		v, err := convert.Slice(row, config.SampleScalarType, opts.WithIdentifier(fmt.Sprintf("%s[%d]", id, i)))
+		if err != nil {
+			return err
+		}
+		rows = append(rows, v)
+		grows = append(grows, guard(v, n))
+
+		if config.Slopes != nil && *config.Slopes {
+			s, err := slopes(v, copts, fmt.Sprintf("%s_slopes[%d]", id, i))
+			if err != nil {
+				return err
+			}
+			slps = append(slps, guard(s, n))
+		}
+
+		shifts = append(shifts, uint8(bits.TrailingZeros(uint(config.SamplesPerCycle/length))))
+	}
+
+	r.AddData(id, renderer.Typed{Type: config.SampleScalarType, Value: renderer.Jagged(grows)}, config.DataAttributes, nil)
+	if n > 0 {
+		renderer.AddPadding(r, id, n)
+	}
+
+	if len(slps) > 0 {
+		r.AddData(id+"_slopes", renderer.Typed{Type: config.SampleScalarType, Value: renderer.Jagged(slps)}, config.DataAttributes, nil)
+		if n > 0 {
+			renderer.AddPadding(r, id+"_slopes", n)
+		}
+	}
+
+	r.AddData(id+"_shifts", shifts, config.DataAttributes, nil)
+
+	return quantization.Report(r, config.QuantizationReport, id, config.SampleScalarType, opts, src, rows)
+}
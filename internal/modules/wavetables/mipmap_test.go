@@ -0,0 +1,134 @@
+package wavetables
+
+import (
+	"bytes"
+	"math/cmplx"
+	"strings"
+	"testing"
+
+	"rafaelmartins.com/p/synth-datagen/internal/codegen"
+	"rafaelmartins.com/p/synth-datagen/internal/convert"
+	"rafaelmartins.com/p/synth-datagen/internal/fft"
+)
+
+// testMipmapSampleRate is high enough for all the harmonics of every row to be below the
+// nyquist frequency, so that the row lengths only depend on their content.
+var testMipmapSampleRate = 1e9
+
+func newTestMipmapRows() ([][]float64, []int) {
+	harmonics := []int{255, 100, 60, 3}
+	rows := [][]float64{}
+	for _, h := range harmonics {
+		amplitudes := make([]float64, h)
+		phases := make([]float64, h)
+		for i := range amplitudes {
+			amplitudes[i] = 1 / float64(i+1)
+			phases[i] = float64(17 * i)
+		}
+		rows = append(rows, additive(512, amplitudes, phases, h))
+	}
+	return rows, harmonics
+}
+
+func TestMipmapLength(t *testing.T) {
+	rows, harmonics := newTestMipmapRows()
+
+	for _, tc := range []struct {
+		minSamples int
+		expected   []int
+	}{
+		{16, []int{512, 256, 128, 16}},
+		{8, []int{512, 256, 128, 8}},
+		{256, []int{512, 256, 256, 256}},
+	} {
+		config := &wavetablesConfig{
+			SamplesPerCycle:             512,
+			SampleRate:                  &testMipmapSampleRate,
+			BandlimitedMipmapMinSamples: &tc.minSamples,
+		}
+		for i, row := range rows {
+			if h := highestHarmonic(row); h != harmonics[i] {
+				t.Errorf("row %d: unexpected highest harmonic: got %d, expected %d", i, h, harmonics[i])
+			}
+			if l := (&Wavetables{}).mipmapLength(config, 0, row); l != tc.expected[i] {
+				t.Errorf("min %d: row %d: unexpected length: got %d, expected %d", tc.minSamples, i, l, tc.expected[i])
+			}
+		}
+	}
+
+	// a row with all the harmonics is still limited by the nyquist frequency of the
+	// highest table row.
+	sampleRate := 48000.
+	config := &wavetablesConfig{
+		SamplesPerCycle: 512,
+		SampleRate:      &sampleRate,
+	}
+	bl := &Wavetables{}
+	n, err := bl.numTables(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := bl.maxHarmonic(config, n-1)
+	if l := bl.mipmapLength(config, n-1, rows[0]); l != mipmapMinSamples || h > (l-1)/2 {
+		t.Errorf("unexpected length for highest row with harmonic %d: %d", h, l)
+	}
+}
+
+func TestMipmapDecimate(t *testing.T) {
+	rows, harmonics := newTestMipmapRows()
+	config := &wavetablesConfig{
+		SamplesPerCycle: 512,
+		SampleRate:      &testMipmapSampleRate,
+	}
+
+	for i, row := range rows {
+		length := (&Wavetables{}).mipmapLength(config, 0, row)
+		d := decimate(row, length)
+		if len(d) != length {
+			t.Fatalf("row %d: unexpected decimated length: got %d, expected %d", i, len(d), length)
+		}
+
+		spec := fft.Forward(fft.Real(row))
+		dspec := fft.Forward(fft.Real(d))
+		scale := complex(float64(len(row))/float64(length), 0)
+		for h := 1; h <= harmonics[i]; h++ {
+			if diff := cmplx.Abs(dspec[h]*scale - spec[h]); diff > 1e-9 {
+				t.Errorf("row %d: harmonic %d changed by decimation: %g", i, h, diff)
+			}
+		}
+	}
+}
+
+func TestMipmapShifts(t *testing.T) {
+	rows, _ := newTestMipmapRows()
+
+	copts, err := convert.NewOptions(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &wavetablesConfig{
+		SamplesPerCycle:  512,
+		SampleRate:       &testMipmapSampleRate,
+		SampleAmplitude:  1,
+		SampleScalarType: "float",
+	}
+
+	h := codegen.NewHeader()
+	if err := (&Wavetables{}).addMipmap(h, config, copts, copts, "osc", rows); err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	if err := h.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	got := buf.String()
+	for _, expected := range []string{
+		"static const uint16_t osc_lengths[4] = {\n    0x0200, 0x0100, 0x0080, 0x0010,\n};",
+		"static const uint8_t osc_shifts[4] = {\n    0x00, 0x01, 0x02, 0x05,\n};",
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("expected %q in output", expected)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package wavetables
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"rafaelmartins.com/p/synth-datagen/internal/wav"
+)
+
+// naiveWaveform returns one cycle of a naive waveform, with unit amplitude.
+func naiveWaveform(name string, samples int) []float64 {
+	rv := make([]float64, 0, samples)
+	for i := 0; i < samples; i++ {
+		switch name {
+		case "sine":
+			rv = append(rv, math.Sin(2*math.Pi*float64(i)/float64(samples)))
+		case "square":
+			rv = append(rv, 1.-2.*math.Floor(2.*float64(i)/float64(samples)))
+		case "triangle":
+			rv = append(rv, 2./math.Pi*math.Asin(math.Sin(2.*math.Pi*float64(i)/float64(samples))))
+		case "sawtooth":
+			rv = append(rv, 1.-2*float64(i)/float64(samples))
+		}
+	}
+	return rv
+}
+
+func (bl *Wavetables) morphKeyframe(config *wavetablesConfig, name string) ([]float64, error) {
+	switch name {
+	case "sine", "square", "triangle", "sawtooth":
+		return naiveWaveform(name, config.SamplesPerCycle), nil
+
+	case "additive":
+		if len(config.AdditiveAmplitudes) == 0 {
+			return nil, fmt.Errorf("wavetables: additive_amplitudes is required by morph frame: %s", name)
+		}
+		return additive(config.SamplesPerCycle, config.AdditiveAmplitudes, config.AdditivePhases, (config.SamplesPerCycle-1)/2), nil
+
+	case "wav":
+		if config.WavFile == nil {
+			return nil, fmt.Errorf("wavetables: wav_file is required by morph frame: %s", name)
+		}
+		cycle, err := wav.Read(*config.WavFile)
+		if err != nil {
+			return nil, err
+		}
+		return synthesize(spectrum(cycle), config.SamplesPerCycle, (config.SamplesPerCycle-1)/2), nil
+	}
+	return nil, fmt.Errorf("wavetables: invalid morph frame: %s", name)
+}
+
+// morphLinear interpolates the samples of 2 waveforms.
+func morphLinear(a []float64, b []float64, t float64) []float64 {
+	rv := make([]float64, 0, len(a))
+	for i := range a {
+		rv = append(rv, (1-t)*a[i]+t*b[i])
+	}
+	return rv
+}
+
+// morphSpectral interpolates the magnitude and phase of each harmonic of 2 waveforms, with
+// the phase following the shortest arc.
+func morphSpectral(a []float64, b []float64, t float64) []float64 {
+	sa := spectrum(a)
+	sb := spectrum(b)
+
+	s := make([]complex128, 0, len(sa))
+	for h := range sa {
+		pa := cmplx.Phase(sa[h])
+		dp := math.Remainder(cmplx.Phase(sb[h])-pa, 2*math.Pi)
+		s = append(s, cmplx.Rect((1-t)*cmplx.Abs(sa[h])+t*cmplx.Abs(sb[h]), pa+t*dp))
+	}
+	return synthesize(s, len(a), len(a))
+}
+
+func (bl *Wavetables) morphFrames(config *wavetablesConfig) ([][]float64, error) {
+	keyframes := make([][]float64, 0, len(config.MorphFrames))
+	for _, name := range config.MorphFrames {
+		kf, err := bl.morphKeyframe(config, name)
+		if err != nil {
+			return nil, err
+		}
+		keyframes = append(keyframes, bl.normalizeWavetable(config, kf))
+	}
+
+	interpolated := 0
+	if config.MorphInterpolatedFrames != nil {
+		if *config.MorphInterpolatedFrames < 0 {
+			return nil, errors.New("wavetables: morph_interpolated_frames must be >= 0")
+		}
+		interpolated = *config.MorphInterpolatedFrames
+	}
+
+	interpolation := "linear"
+	if config.MorphInterpolation != nil {
+		interpolation = *config.MorphInterpolation
+	}
+
+	var morph func(a []float64, b []float64, t float64) []float64
+	switch interpolation {
+	case "linear":
+		morph = morphLinear
+	case "spectral":
+		morph = morphSpectral
+	default:
+		return nil, fmt.Errorf("wavetables: invalid morph interpolation: %s", interpolation)
+	}
+
+	rv := make([][]float64, 0, (len(keyframes)-1)*(interpolated+1)+1)
+	for i, kf := range keyframes {
+		rv = append(rv, kf)
+		if i == len(keyframes)-1 {
+			break
+		}
+		for j := 1; j <= interpolated; j++ {
+			rv = append(rv, bl.normalizeWavetable(config, morph(kf, keyframes[i+1], float64(j)/float64(interpolated+1))))
+		}
+	}
+	return rv, nil
+}
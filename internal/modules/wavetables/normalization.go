@@ -0,0 +1,100 @@
+package wavetables
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+func rms(data []float64) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+
+	mean := 0.
+	for _, v := range data {
+		mean += v
+	}
+	mean /= float64(len(data))
+
+	sum := 0.
+	for _, v := range data {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(data)))
+}
+
+// targetRMS returns the RMS level that waveforms are normalized to, or 0 for peak normalization.
+func (bl *Wavetables) targetRMS(config *wavetablesConfig) (float64, error) {
+	mode := "peak"
+	if config.Normalization != nil {
+		mode = *config.Normalization
+	}
+
+	switch mode {
+	case "peak":
+		return 0, nil
+
+	case "reference":
+		ref := "sine"
+		if config.NormalizationReference != nil {
+			ref = *config.NormalizationReference
+		}
+
+		switch ref {
+		case "sine", "square", "triangle", "sawtooth":
+			return rms(bl.scale(config, naiveWaveform(ref, config.SamplesPerCycle))), nil
+		}
+		return 0, fmt.Errorf("wavetables: invalid normalization reference: %s", ref)
+
+	case "rms":
+		if config.NormalizationRms == nil {
+			return 0, errors.New("wavetables: normalization_rms is required by rms normalization")
+		}
+		if *config.NormalizationRms <= 0 {
+			return 0, errors.New("wavetables: normalization_rms must be > 0")
+		}
+		return *config.NormalizationRms, nil
+	}
+	return 0, fmt.Errorf("wavetables: invalid normalization: %s", mode)
+}
+
+// normalizeCycle scales a peak normalized cycle to the target RMS level, if any, and checks
+// that the scaled peak still fits sample_amplitude.
+func (bl *Wavetables) normalizeCycle(config *wavetablesConfig, identifier string, data []float64) ([]float64, error) {
+	target, err := bl.targetRMS(config)
+	if err != nil {
+		return nil, err
+	}
+
+	r := rms(data)
+	if target == 0 || r == 0 {
+		return data, nil
+	}
+
+	factor := target / r
+	peak := 0.
+	rv := make([]float64, 0, len(data))
+	for _, v := range data {
+		rv = append(rv, v*factor)
+		peak = max(peak, math.Abs(v*factor))
+	}
+
+	// allow for rounding errors of waveforms that are already at the target level
+	if peak > config.SampleAmplitude*(1+1e-9) {
+		return nil, fmt.Errorf("wavetables: %s: normalized peak %g exceeds sample_amplitude %g", identifier, peak, config.SampleAmplitude)
+	}
+	return rv, nil
+}
+
+func (bl *Wavetables) normalizeRows(config *wavetablesConfig, identifier string, data [][]float64) ([][]float64, error) {
+	rv := make([][]float64, 0, len(data))
+	for i, row := range data {
+		r, err := bl.normalizeCycle(config, fmt.Sprintf("%s[%d]", identifier, i), row)
+		if err != nil {
+			return nil, err
+		}
+		rv = append(rv, r)
+	}
+	return rv, nil
+}
@@ -0,0 +1,28 @@
+package wavetables
+
+// noteTable returns the band-limited table row used by each MIDI note, and the weight of
+// the next row when crossfading between adjacent rows. Notes above the last row (omitted
+// by bandlimited_omit_high_octaves) use the last row, without crossfading.
+func (bl *Wavetables) noteTable(config *wavetablesConfig) ([]uint8, []float64, error) {
+	numTables, err := bl.numTables(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	semitones := bl.semitones(config)
+	rows := make([]uint8, 0, 128)
+	weights := make([]float64, 0, 128)
+	for note := range 128 {
+		row := note / semitones
+		if row >= numTables-1 {
+			rows = append(rows, uint8(numTables-1))
+			weights = append(weights, 0)
+			continue
+		}
+
+		lo, _ := bl.tableNotes(config, row)
+		rows = append(rows, uint8(row))
+		weights = append(weights, float64(note-lo)/float64(semitones))
+	}
+	return rows, weights, nil
+}
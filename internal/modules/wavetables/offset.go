@@ -0,0 +1,63 @@
+package wavetables
+
+import (
+	"errors"
+	"fmt"
+
+	"rafaelmartins.com/p/synth-datagen/internal/convert"
+)
+
+// sampleOffset returns the offset added to the samples of the waveforms, that are otherwise
+// symmetric around zero.
+func (bl *Wavetables) sampleOffset(config *wavetablesConfig) float64 {
+	if config.SampleOffset != nil {
+		return *config.SampleOffset
+	}
+	if config.Unipolar != nil && *config.Unipolar {
+		return config.SampleAmplitude
+	}
+	return 0
+}
+
+// checkSampleOffset checks that the range of the offset waveforms fits the scalar type.
+func (bl *Wavetables) checkSampleOffset(config *wavetablesConfig, copts *convert.Options) error {
+	if config.SampleOffset != nil && config.Unipolar != nil && *config.Unipolar {
+		return errors.New("wavetables: unipolar and sample_offset can't be used together")
+	}
+	if config.SampleOffset == nil && (config.Unipolar == nil || !*config.Unipolar) {
+		return nil
+	}
+
+	min, max, err := convert.Limits(config.SampleScalarType, copts)
+	if err != nil {
+		return err
+	}
+
+	offset := bl.sampleOffset(config)
+	if offset-config.SampleAmplitude < min || offset+config.SampleAmplitude > max {
+		return fmt.Errorf("wavetables: sample range [%g, %g] doesn't fit %s range [%g, %g]",
+			offset-config.SampleAmplitude, offset+config.SampleAmplitude, config.SampleScalarType, min, max)
+	}
+	return nil
+}
+
+func (bl *Wavetables) offsetCycle(config *wavetablesConfig, data []float64) []float64 {
+	offset := bl.sampleOffset(config)
+	if offset == 0 {
+		return data
+	}
+
+	rv := make([]float64, 0, len(data))
+	for _, v := range data {
+		rv = append(rv, v+offset)
+	}
+	return rv
+}
+
+func (bl *Wavetables) offsetRows(config *wavetablesConfig, data [][]float64) [][]float64 {
+	rv := make([][]float64, 0, len(data))
+	for _, row := range data {
+		rv = append(rv, bl.offsetCycle(config, row))
+	}
+	return rv
+}
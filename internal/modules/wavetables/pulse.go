@@ -0,0 +1,40 @@
+package wavetables
+
+import (
+	"math"
+)
+
+// pulseWaveform returns one cycle of a naive pulse wave with the given duty cycle, with unit
+// amplitude.
+func pulseWaveform(duty float64, samples int) []float64 {
+	rv := make([]float64, 0, samples)
+	for i := 0; i < samples; i++ {
+		if float64(i) < duty*float64(samples) {
+			rv = append(rv, 1)
+		} else {
+			rv = append(rv, -1)
+		}
+	}
+	return rv
+}
+
+// pulseFromSawtooth returns a pulse wave with the given duty cycle, computed as the difference
+// of the sawtooth and its copy shifted by the duty cycle, with the DC component removed.
+func pulseFromSawtooth(sawtooth []float64, duty float64) []float64 {
+	n := len(sawtooth)
+	shift := int(math.Round(duty * float64(n)))
+
+	rv := make([]float64, 0, n)
+	sum := 0.
+	for i := range sawtooth {
+		v := sawtooth[i] - sawtooth[(i+shift)%n]
+		rv = append(rv, v)
+		sum += v
+	}
+
+	avg := sum / float64(n)
+	for i := range rv {
+		rv[i] -= avg
+	}
+	return rv
+}
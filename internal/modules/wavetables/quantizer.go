@@ -0,0 +1,112 @@
+package wavetables
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"rafaelmartins.com/p/synth-datagen/internal/convert"
+)
+
+const quantizerSeed = 1
+
+// quantize rounds a dithered value to the nearest integer, saturated to the range of the
+// type. rounding to nearest keeps the dither unbiased, regardless of the rounding mode.
+func quantize(v float64, min float64, max float64) float64 {
+	return math.Min(math.Max(math.Round(v), min), max)
+}
+
+// tpdfQuantizer adds triangular probability density function dither, with a peak
+// amplitude of 1 LSB, before rounding.
+type tpdfQuantizer struct {
+	rng *rand.Rand
+}
+
+func (q *tpdfQuantizer) dither(n int) []float64 {
+	rv := make([]float64, 0, n)
+	for range n {
+		rv = append(rv, q.rng.Float64()-q.rng.Float64())
+	}
+	return rv
+}
+
+func (q *tpdfQuantizer) Quantize(values []float64, min float64, max float64) []float64 {
+	d := q.dither(len(values))
+	for i := range values {
+		values[i] = quantize(values[i]+d[i], min, max)
+	}
+	return values
+}
+
+// noiseShapingQuantizer is a first order error feedback quantizer, with TPDF dither, that
+// moves the quantization noise to the higher frequencies (noise transfer function is
+// 1 - z^-1). The feedback loop runs twice over the table, and only the second run is kept,
+// so that the error of the last sample is fed back to the first one, as happens when the
+// table is played in a loop.
+type noiseShapingQuantizer struct {
+	tpdfQuantizer
+}
+
+func (q *noiseShapingQuantizer) Quantize(values []float64, min float64, max float64) []float64 {
+	d := q.dither(len(values))
+	rv := make([]float64, len(values))
+	e := 0.
+	for range 2 {
+		for i, v := range values {
+			u := v - e
+			rv[i] = quantize(u+d[i], min, max)
+			e = rv[i] - u
+		}
+	}
+	return rv
+}
+
+// newQuantizer returns the quantizer of a selector. each selector has its own dither
+// stream, so that tables generated with the same seed don't share the same dither.
+func (bl *Wavetables) newQuantizer(mode string, seed uint64, sel string) (convert.Quantizer, error) {
+	stream := uint64(slices.Index(bl.GetAllowedSelectors(), sel))
+	switch mode {
+	case "none":
+		return nil, nil
+	case "tpdf":
+		return &tpdfQuantizer{
+			rng: rand.New(rand.NewPCG(seed, stream)),
+		}, nil
+	case "noise_shaping":
+		return &noiseShapingQuantizer{
+			tpdfQuantizer: tpdfQuantizer{
+				rng: rand.New(rand.NewPCG(seed, stream)),
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("wavetables: invalid quantizer: %s", mode)
+}
+
+// selectorOptions returns the conversion options of a table, with the quantizer selected
+// for its selector in the `quantizer` mapping, if any.
+func (bl *Wavetables) selectorOptions(config *wavetablesConfig, copts *convert.Options, sel string, identifier string) (*convert.Options, error) {
+	rv := copts.WithIdentifier(identifier)
+
+	m, ok := config.Quantizer[sel]
+	if !ok {
+		return rv, nil
+	}
+
+	mode, ok := m.(string)
+	if !ok {
+		return nil, fmt.Errorf("wavetables: quantizer for selector %q is not a string", sel)
+	}
+
+	seed := uint64(quantizerSeed)
+	if config.QuantizerSeed != nil {
+		seed = *config.QuantizerSeed
+	}
+
+	q, err := bl.newQuantizer(mode, seed, sel)
+	if err != nil {
+		return nil, err
+	}
+	rv.Quantizer = q
+	return rv, nil
+}
@@ -0,0 +1,133 @@
+package wavetables
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"rafaelmartins.com/p/synth-datagen/internal/convert"
+	"rafaelmartins.com/p/synth-datagen/internal/fft"
+)
+
+func newTestQuantizers() map[string]convert.Quantizer {
+	return map[string]convert.Quantizer{
+		"tpdf": &tpdfQuantizer{
+			rng: rand.New(rand.NewPCG(1, 2)),
+		},
+		"noise_shaping": &noiseShapingQuantizer{
+			tpdfQuantizer: tpdfQuantizer{
+				rng: rand.New(rand.NewPCG(1, 2)),
+			},
+		},
+	}
+}
+
+func TestQuantizerUnbiased(t *testing.T) {
+	for name, q := range newTestQuantizers() {
+		t.Run(name, func(t *testing.T) {
+			values := make([]float64, 1<<14)
+			for i := range values {
+				values[i] = 10.3
+			}
+
+			mean := 0.
+			for _, v := range q.Quantize(values, -128, 127) {
+				if v != math.Round(v) {
+					t.Fatalf("value is not integral: %g", v)
+				}
+				mean += v
+			}
+			mean /= float64(len(values))
+			if math.Abs(mean-10.3) > 0.02 {
+				t.Errorf("biased quantization: mean %g, expected 10.3", mean)
+			}
+		})
+	}
+}
+
+func TestQuantizerSaturate(t *testing.T) {
+	for name, q := range newTestQuantizers() {
+		t.Run(name, func(t *testing.T) {
+			values := make([]float64, 512)
+			for i := range values {
+				values[i] = 32767
+				if i >= len(values)/2 {
+					values[i] = -32768
+				}
+			}
+
+			for i, v := range q.Quantize(slices.Clone(values), -32768, 32767) {
+				if v < -32768 || v > 32767 {
+					t.Fatalf("sample %d out of range: %g", i, v)
+				}
+				if math.Abs(v-values[i]) > 2 {
+					t.Errorf("sample %d: expected %g, got %g", i, values[i], v)
+				}
+			}
+		})
+	}
+}
+
+func TestQuantizerNoiseShaping(t *testing.T) {
+	// the error of the noise shaping quantizer must be moved to the higher frequencies,
+	// while the error of the tpdf quantizer is flat.
+	values := make([]float64, 1<<12)
+	for i := range values {
+		values[i] = 100 * math.Sin(2*math.Pi*float64(i)/float64(len(values)))
+	}
+
+	power := func(q convert.Quantizer) (float64, float64) {
+		out := q.Quantize(slices.Clone(values), -128, 127)
+		e := make([]float64, 0, len(values))
+		for i := range values {
+			e = append(e, out[i]-values[i])
+		}
+
+		spec := fft.Forward(fft.Real(e))
+		low, high := 0., 0.
+		for k := 1; k < len(values)/8; k++ {
+			low += cmplx.Abs(spec[k]) * cmplx.Abs(spec[k])
+			high += cmplx.Abs(spec[len(values)/2-k]) * cmplx.Abs(spec[len(values)/2-k])
+		}
+		return low, high
+	}
+
+	qs := newTestQuantizers()
+	if low, high := power(qs["noise_shaping"]); low*10 > high {
+		t.Errorf("noise shaping: low frequency error power %g not below high frequency power %g", low, high)
+	}
+	if low, high := power(qs["tpdf"]); low*2 < high || high*2 < low {
+		t.Errorf("tpdf: error spectrum not flat: low %g, high %g", low, high)
+	}
+}
+
+func TestQuantizerStreams(t *testing.T) {
+	bl := &Wavetables{}
+	values := make([]float64, 64)
+
+	out := [][]float64{}
+	for _, sel := range []string{"sine", "sawtooth"} {
+		q, err := bl.newQuantizer("tpdf", 1, sel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, q.Quantize(slices.Clone(values), -128, 127))
+	}
+	if slices.Equal(out[0], out[1]) {
+		t.Error("selectors share the same dither sequence")
+	}
+
+	q, err := bl.newQuantizer("tpdf", 1, "sine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(out[0], q.Quantize(slices.Clone(values), -128, 127)) {
+		t.Error("same seed and selector generated different dither")
+	}
+
+	if _, err := bl.newQuantizer("bad", 1, "sine"); err == nil || err.Error() != "wavetables: invalid quantizer: bad" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package wavetables
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"rafaelmartins.com/p/synth-datagen/internal/ctypes"
+)
+
+// quarterWave returns the first quadrant of one cycle of a sine or cosine wave, plus one
+// sample, with unit amplitude.
+func quarterWave(name string, samples int) []float64 {
+	rv := make([]float64, 0, samples/4+1)
+	for i := 0; i <= samples/4; i++ {
+		switch name {
+		case "sine_quarter":
+			rv = append(rv, math.Sin(2*math.Pi*float64(i)/float64(samples)))
+		case "cosine_quarter":
+			rv = append(rv, math.Cos(2*math.Pi*float64(i)/float64(samples)))
+		}
+	}
+	return rv
+}
+
+// quarterWaveAccessor returns a static inline function that reads a sample of the full cycle
+// from a quarter wave table, given an index in the [0, samples) range. Negated values are
+// reflected around bias / 2, the stored sample offset.
+func quarterWaveAccessor(name string, identifier string, scalarType string, samples int, bias float64) (string, error) {
+	typ, err := ctypes.ToType(scalarType)
+	if err != nil {
+		return "", err
+	}
+	ctype, err := ctypes.FromType(typ)
+	if err != nil {
+		return "", err
+	}
+
+	itype := "uint32_t"
+	if samples <= 1<<8 {
+		itype = "uint8_t"
+	} else if samples <= 1<<16 {
+		itype = "uint16_t"
+	}
+
+	q := samples / 4
+
+	// sine is mirrored in the second and fourth quadrants and negated in the second half,
+	// cosine is mirrored in the second and fourth quadrants and negated in the middle half.
+	quadrants := []string{
+		fmt.Sprintf("%s[index]", identifier),
+		fmt.Sprintf("%s[%d - index]", identifier, 2*q),
+		fmt.Sprintf("%s[index - %d]", identifier, 2*q),
+		fmt.Sprintf("%s[%d - index]", identifier, 4*q),
+	}
+	negate := []bool{false, false, true, true}
+	if name == "cosine_quarter" {
+		negate = []bool{false, true, true, false}
+	}
+
+	rv := strings.Builder{}
+	fmt.Fprintf(&rv, "static inline %s %s_read(%s index) {\n", ctype, identifier, itype)
+	if samples != 1<<8 && samples != 1<<16 {
+		fmt.Fprintf(&rv, "    index %%= %d;\n", samples)
+	}
+	for i, quad := range quadrants {
+		ret := quad
+		if negate[i] {
+			if bias == 0 {
+				ret = fmt.Sprintf("(%s) -%s", ctype, quad)
+			} else if typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64 {
+				ret = fmt.Sprintf("(%s) (%s - %s)", ctype, strconv.FormatFloat(bias, 'g', -1, 64), quad)
+			} else {
+				ret = fmt.Sprintf("(%s) (%d - %s)", ctype, int64(math.Round(bias)), quad)
+			}
+		}
+		if i < len(quadrants)-1 {
+			fmt.Fprintf(&rv, "    if (index < %d)\n        return %s;\n", (i+1)*q, ret)
+		} else {
+			fmt.Fprintf(&rv, "    return %s;\n", ret)
+		}
+	}
+	rv.WriteString("}\n")
+	return rv.String(), nil
+}
@@ -0,0 +1,34 @@
+package wavetables
+
+import (
+	"math/cmplx"
+
+	"rafaelmartins.com/p/synth-datagen/internal/fft"
+)
+
+// spectrum returns the harmonics of one cycle of a waveform, scaled by the cycle length.
+// Only harmonics below the nyquist frequency of the cycle are returned, starting from
+// the DC component.
+func spectrum(cycle []float64) []complex128 {
+	rv := fft.Forward(fft.Real(cycle))[:(len(cycle)+1)/2]
+	for i := range rv {
+		rv[i] /= complex(float64(len(cycle)), 0)
+	}
+	return rv
+}
+
+// synthesize returns one cycle of samples samples from the harmonics of spec, up to
+// maxHarmonic. The DC component is discarded.
+func synthesize(spec []complex128, samples int, maxHarmonic int) []float64 {
+	s := make([]complex128, samples)
+	for h := 1; h < len(spec) && h <= maxHarmonic && h < (samples+1)/2; h++ {
+		s[h] = spec[h] * complex(float64(samples), 0)
+		s[samples-h] = cmplx.Conj(s[h])
+	}
+
+	rv := make([]float64, 0, samples)
+	for _, v := range fft.Inverse(s) {
+		rv = append(rv, real(v))
+	}
+	return rv
+}
@@ -1,25 +1,57 @@
 package wavetables
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"slices"
 
 	"rafaelmartins.com/p/synth-datagen/internal/convert"
 	"rafaelmartins.com/p/synth-datagen/internal/datareg"
+	"rafaelmartins.com/p/synth-datagen/internal/quantization"
 	"rafaelmartins.com/p/synth-datagen/internal/renderer"
 	"rafaelmartins.com/p/synth-datagen/internal/selector"
+	"rafaelmartins.com/p/synth-datagen/internal/wav"
 )
 
 type Wavetables struct{}
 
 type wavetablesConfig struct {
-	SamplesPerCycle            int
-	SampleAmplitude            float64
-	SampleScalarType           string
-	DataAttributes             []string
-	A4Frequency                *float64
-	SampleRate                 *float64 `selectors:"blsquare,bltriangle,blsawtooth"`
-	BandlimitedOmitHighOctaves *int
+	SamplesPerCycle               int
+	SampleAmplitude               float64
+	SampleScalarType              string
+	DataAttributes                []string
+	A4Frequency                   *float64
+	SampleRate                    *float64 `selectors:"blsquare,bltriangle,blsawtooth,blpulse,additive,wav"`
+	BandlimitedOmitHighOctaves    *int
+	BandlimitedSemitones          *int
+	BandlimitedMipmap             *bool
+	BandlimitedMipmapMinSamples   *int
+	BandlimitedNoAliasing         *bool
+	AdditiveAmplitudes            []float64 `selectors:"additive"`
+	AdditivePhases                []float64
+	WavFile                       *string   `selectors:"wav" path:"true"`
+	PulseDutyCycles               []float64 `selectors:"pulse,blpulse"`
+	MorphFrames                   []string  `selectors:"morph"`
+	MorphInterpolatedFrames       *int
+	MorphInterpolation            *string
+	MorphBandlimited              *bool
+	Normalization                 *string
+	NormalizationReference        *string
+	NormalizationRms              *float64
+	Unipolar                      *bool
+	SampleOffset                  *float64
+	GuardSamples                  *int
+	Slopes                        *bool
+	BlCrossfadeScalarType         *string `selectors:"bl_crossfade"`
+	BlCrossfadeFractionalBitWidth *uint8
+	BlepOversampling              *int
+	BlepZeroCrossings             *int
+	QuantizationReport            *string
+	Quantizer                     map[string]any
+	QuantizerSeed                 *uint64
+	Rounding                      *string
+	Overflow                      *string
 }
 
 func (*Wavetables) GetName() string {
@@ -27,10 +59,10 @@ func (*Wavetables) GetName() string {
 }
 
 func (*Wavetables) GetAllowedSelectors() []string {
-	return []string{"sine", "square", "triangle", "sawtooth", "blsquare", "bltriangle", "blsawtooth"}
+	return []string{"sine", "square", "triangle", "sawtooth", "sine_quarter", "cosine_quarter", "pulse", "blsquare", "bltriangle", "blsawtooth", "blpulse", "additive", "wav", "morph", "minblep", "blamp", "polyblep", "bl_note_table", "bl_crossfade"}
 }
 
-func (bl *Wavetables) fixWavetable(config *wavetablesConfig, data []float64) []float64 {
+func (bl *Wavetables) normalizeWavetable(config *wavetablesConfig, data []float64) []float64 {
 	min := 0.
 	max := 0.
 	if len(data) > 0 {
@@ -47,89 +79,314 @@ func (bl *Wavetables) fixWavetable(config *wavetablesConfig, data []float64) []f
 		}
 	}
 
-	scaleFactor := (2 * config.SampleAmplitude) / math.Abs(max-min)
 	rv := make([]float64, config.SamplesPerCycle)
+	if max == min {
+		return rv
+	}
+
+	scaleFactor := (2 * config.SampleAmplitude) / math.Abs(max-min)
 	for i := range data {
-		// reverse the wavetable to match the naive waveforms
-		rv[len(data)-i-1] = (data[i]-min)*scaleFactor - config.SampleAmplitude
+		rv[i] = (data[i]-min)*scaleFactor - config.SampleAmplitude
 	}
 	return rv
 }
 
-func (bl *Wavetables) Render(r renderer.Renderer, identifier string, dreg *datareg.DataReg, pmt map[string]any, slt *selector.Selector) error {
-	config := wavetablesConfig{}
-	if err := dreg.Evaluate(bl.GetName(), &config, pmt, slt); err != nil {
-		return err
+func (bl *Wavetables) fixWavetable(config *wavetablesConfig, data []float64) []float64 {
+	rv := bl.normalizeWavetable(config, data)
+
+	// reverse the wavetable to match the naive waveforms
+	slices.Reverse(rv[:len(data)])
+	return rv
+}
+
+func (bl *Wavetables) referenceFrequency(config *wavetablesConfig) float64 {
+	if config.A4Frequency != nil {
+		return *config.A4Frequency
+	}
+	return a4Frequency
+}
+
+// numTables returns the number of rows of the band-limited tables. Each row covers
+// bandlimited_semitones notes, and rows that only cover omitted octaves are not generated.
+func (bl *Wavetables) numTables(config *wavetablesConfig) (int, error) {
+	octaves := int(math.Ceil(128.0 / 12))
+	notes := 128
+	if config.BandlimitedOmitHighOctaves != nil {
+		if *config.BandlimitedOmitHighOctaves < 0 || *config.BandlimitedOmitHighOctaves >= octaves {
+			return 0, fmt.Errorf("wavetables: bandlimited_omit_high_octaves must be >= 0 and < %d", octaves)
+		}
+		notes -= 12 * *config.BandlimitedOmitHighOctaves
+	}
+
+	semitones := bl.semitones(config)
+	if semitones < 1 || semitones > 128 {
+		return 0, errors.New("wavetables: bandlimited_semitones must be >= 1 and <= 128")
+	}
+	return (notes + semitones - 1) / semitones, nil
+}
+
+func (bl *Wavetables) semitones(config *wavetablesConfig) int {
+	if config.BandlimitedSemitones != nil {
+		return *config.BandlimitedSemitones
+	}
+	return 12
+}
+
+// tableNotes returns the lowest and highest MIDI notes covered by a band-limited table row.
+func (bl *Wavetables) tableNotes(config *wavetablesConfig, row int) (int, int) {
+	semitones := bl.semitones(config)
+	return min(row*semitones, 127), min((row+1)*semitones-1, 127)
+}
+
+// tableFrequency returns the frequency used to band-limit a table row. By default it is
+// the geometric mean of the frequencies of the lowest and highest notes of the row, that
+// allows some aliasing for the highest notes. With bandlimited_no_aliasing, the highest
+// note frequency is used instead, at the cost of fewer harmonics for the lowest notes.
+func (bl *Wavetables) tableFrequency(config *wavetablesConfig, row int) float64 {
+	lo, hi := bl.tableNotes(config, row)
+	if config.BandlimitedNoAliasing != nil && *config.BandlimitedNoAliasing {
+		return noteFrequency(hi, bl.referenceFrequency(config))
 	}
+	return wavetableFrequency(lo, hi, bl.referenceFrequency(config))
+}
 
-	if slt.IsSelected("sine") {
-		sine := make([]float64, 0, config.SamplesPerCycle)
-		for i := 0; i < config.SamplesPerCycle; i++ {
-			sine = append(sine, float64(config.SampleAmplitude*math.Sin(2*math.Pi*float64(i)/float64(config.SamplesPerCycle))))
+// maxHarmonic returns the highest harmonic of the wavetable row that is below the
+// nyquist frequency, for both the sample rate and the wavetable size.
+func (bl *Wavetables) maxHarmonic(config *wavetablesConfig, row int) int {
+	freq := bl.tableFrequency(config, row)
+	rv := int(math.Ceil(*config.SampleRate/(2*freq))) - 1
+	return max(min(rv, (config.SamplesPerCycle-1)/2), 0)
+}
+
+// bandlimited returns true if any of the selected tables has one row per band-limited table.
+func (bl *Wavetables) bandlimited(config *wavetablesConfig, slt *selector.Selector) bool {
+	for _, sel := range []string{"blsquare", "bltriangle", "blsawtooth", "blpulse", "additive", "wav"} {
+		if slt.IsSelected(sel) {
+			return true
 		}
-		v, err := convert.Slice(sine, config.SampleScalarType)
+	}
+	return slt.IsSelected("morph") && config.MorphBandlimited != nil && *config.MorphBandlimited
+}
+
+func (bl *Wavetables) scale(config *wavetablesConfig, data []float64) []float64 {
+	for i := range data {
+		data[i] *= config.SampleAmplitude
+	}
+	return data
+}
+
+func (bl *Wavetables) addTable(r renderer.Renderer, config *wavetablesConfig, copts *convert.Options, identifier string, sel string, data []float64) error {
+	id := identifier + "_" + sel
+	opts, err := bl.selectorOptions(config, copts, sel, id)
+	if err != nil {
+		return err
+	}
+
+	data, err = bl.normalizeCycle(config, id, data)
+	if err != nil {
+		return err
+	}
+
+	data = bl.offsetCycle(config, data)
+	v, err := convert.Slice(data, config.SampleScalarType, opts)
+	if err != nil {
+		return err
+	}
+	if err := bl.addCycles(r, config, copts, id, v); err != nil {
+		return err
+	}
+	return quantization.Report(r, config.QuantizationReport, id, config.SampleScalarType, opts, data, v)
+}
+
+// addRawTable adds a table that is not a full cycle, without guard samples and slopes.
+func (bl *Wavetables) addRawTable(r renderer.Renderer, config *wavetablesConfig, copts *convert.Options, identifier string, sel string, data []float64) error {
+	id := identifier + "_" + sel
+	opts, err := bl.selectorOptions(config, copts, sel, id)
+	if err != nil {
+		return err
+	}
+
+	v, err := convert.Slice(data, config.SampleScalarType, opts)
+	if err != nil {
+		return err
+	}
+	r.AddData(id, renderer.Typed{Type: config.SampleScalarType, Value: v}, config.DataAttributes, nil)
+	return quantization.Report(r, config.QuantizationReport, id, config.SampleScalarType, opts, data, v)
+}
+
+func (bl *Wavetables) addRows(r renderer.Renderer, config *wavetablesConfig, copts *convert.Options, identifier string, sel string, data [][]float64) error {
+	id := identifier + "_" + sel
+	opts, err := bl.selectorOptions(config, copts, sel, id)
+	if err != nil {
+		return err
+	}
+
+	data, err = bl.normalizeRows(config, id, data)
+	if err != nil {
+		return err
+	}
+
+	data = bl.offsetRows(config, data)
+	if bl.mipmap(config, sel) {
+		return bl.addMipmap(r, config, copts, opts, id, data)
+	}
+
+	rv := make([]any, 0, len(data))
+	for i, row := range data {
+		v, err := convert.Slice(row, config.SampleScalarType, opts.WithIdentifier(fmt.Sprintf("%s[%d]", id, i)))
 		if err != nil {
 			return err
 		}
-		r.AddData(identifier+"_sine", v, config.DataAttributes, nil)
+		rv = append(rv, v)
+	}
+	if err := bl.addCycles(r, config, copts, id, rv); err != nil {
+		return err
 	}
+	return quantization.Report(r, config.QuantizationReport, id, config.SampleScalarType, opts, data, rv)
+}
 
-	if slt.IsSelected("square") {
-		square := make([]float64, 0, config.SamplesPerCycle)
-		for i := 0; i < config.SamplesPerCycle; i++ {
-			square = append(square, float64(config.SampleAmplitude*(1.-2.*math.Floor(2.*float64(i)/float64(config.SamplesPerCycle)))))
-		}
-		v, err := convert.Slice(square, config.SampleScalarType)
+func (bl *Wavetables) addFrames(r renderer.Renderer, config *wavetablesConfig, copts *convert.Options, identifier string, sel string, data [][][]float64) error {
+	id := identifier + "_" + sel
+	opts, err := bl.selectorOptions(config, copts, sel, id)
+	if err != nil {
+		return err
+	}
+
+	frames := make([][][]float64, 0, len(data))
+	for i, frame := range data {
+		f, err := bl.normalizeRows(config, fmt.Sprintf("%s[%d]", id, i), frame)
 		if err != nil {
 			return err
 		}
-		r.AddData(identifier+"_square", v, config.DataAttributes, nil)
+		frames = append(frames, bl.offsetRows(config, f))
 	}
+	data = frames
+	bl.mipmapUnsupported(config, id, sel)
 
-	if slt.IsSelected("triangle") {
-		triangle := make([]float64, 0, config.SamplesPerCycle)
-		for i := 0; i < config.SamplesPerCycle; i++ {
-			triangle = append(triangle, float64(config.SampleAmplitude*(2./math.Pi*math.Asin(math.Sin(2.*math.Pi*float64(i)/float64(config.SamplesPerCycle))))))
-		}
-		v, err := convert.Slice(triangle, config.SampleScalarType)
+	rv := make([]any, 0, len(data))
+	for i, frame := range data {
+		v, err := convert.Slice(frame, config.SampleScalarType, opts.WithIdentifier(fmt.Sprintf("%s[%d]", id, i)))
 		if err != nil {
 			return err
 		}
-		r.AddData(identifier+"_triangle", v, config.DataAttributes, nil)
+		rv = append(rv, v)
+	}
+	if err := bl.addCycles(r, config, copts, id, rv); err != nil {
+		return err
+	}
+	return quantization.Report(r, config.QuantizationReport, id, config.SampleScalarType, opts, data, rv)
+}
+
+func (bl *Wavetables) Render(r renderer.Renderer, identifier string, dreg *datareg.DataReg, pmt map[string]any, slt *selector.Selector) error {
+	config := wavetablesConfig{}
+	if err := dreg.Evaluate(bl.GetName(), &config, pmt, slt); err != nil {
+		return err
+	}
+
+	copts, err := convert.NewOptions(config.Rounding, config.Overflow)
+	if err != nil {
+		return err
+	}
+
+	for _, duty := range config.PulseDutyCycles {
+		if duty <= 0 || duty >= 1 {
+			return fmt.Errorf("wavetables: pulse_duty_cycles must be > 0 and < 1: %g", duty)
+		}
 	}
 
-	if slt.IsSelected("sawtooth") {
-		sawtooth := make([]float64, 0, config.SamplesPerCycle)
-		for i := 0; i < config.SamplesPerCycle; i++ {
-			sawtooth = append(sawtooth, float64(config.SampleAmplitude*(1.-2*float64(i)/float64(config.SamplesPerCycle))))
+	if config.GuardSamples != nil && (*config.GuardSamples < 0 || *config.GuardSamples > config.SamplesPerCycle) {
+		return fmt.Errorf("wavetables: guard_samples must be >= 0 and <= %d", config.SamplesPerCycle)
+	}
+
+	if _, err := bl.targetRMS(&config); err != nil {
+		return err
+	}
+
+	if err := bl.checkSampleOffset(&config, copts); err != nil {
+		return err
+	}
+
+	for sel := range config.Quantizer {
+		if !slices.Contains(bl.GetAllowedSelectors(), sel) {
+			return fmt.Errorf("wavetables: invalid quantizer selector: %s", sel)
 		}
-		v, err := convert.Slice(sawtooth, config.SampleScalarType)
-		if err != nil {
+	}
+
+	if config.BandlimitedSemitones != nil && bl.bandlimited(&config, slt) {
+		if _, err := bl.numTables(&config); err != nil {
 			return err
 		}
-		r.AddData(identifier+"_sawtooth", v, config.DataAttributes, nil)
+		r.AddMacro(identifier+"_bandlimited_semitones", *config.BandlimitedSemitones, false, false)
 	}
 
-	if slt.IsSelected("blsquare") || slt.IsSelected("bltriangle") || slt.IsSelected("blsawtooth") {
-		a4Freq := a4Frequency
-		if config.A4Frequency != nil {
-			a4Freq = *config.A4Frequency
+	for _, sel := range []string{"sine", "square", "triangle", "sawtooth"} {
+		if slt.IsSelected(sel) {
+			if err := bl.addTable(r, &config, copts, identifier, sel, bl.scale(&config, naiveWaveform(sel, config.SamplesPerCycle))); err != nil {
+				return err
+			}
 		}
+	}
+
+	for _, sel := range []string{"sine_quarter", "cosine_quarter"} {
+		if slt.IsSelected(sel) {
+			if config.SamplesPerCycle%4 != 0 {
+				return fmt.Errorf("wavetables: samples_per_cycle must be a multiple of 4 for %s", sel)
+			}
 
-		numOctaves := int(math.Ceil(128.0 / 12))
-		if config.BandlimitedOmitHighOctaves != nil {
-			if *config.BandlimitedOmitHighOctaves < 0 || *config.BandlimitedOmitHighOctaves >= numOctaves {
-				return fmt.Errorf("wavetables: bandlimited_omit_high_octaves must be >= 0 and < %d", numOctaves)
+			// quarter waves are normalized like the full sine wave
+			sine := bl.scale(&config, naiveWaveform("sine", config.SamplesPerCycle))
+			nsine, err := bl.normalizeCycle(&config, identifier+"_"+sel, sine)
+			if err != nil {
+				return err
 			}
-			numOctaves -= *config.BandlimitedOmitHighOctaves
+
+			quarter := bl.scale(&config, quarterWave(sel, config.SamplesPerCycle))
+			if factor := rms(nsine) / rms(sine); factor != 1 {
+				for i := range quarter {
+					quarter[i] *= factor
+				}
+			}
+
+			if err := bl.addRawTable(r, &config, copts, identifier, sel, bl.offsetCycle(&config, quarter)); err != nil {
+				return err
+			}
+
+			scale, err := convert.Scale(config.SampleScalarType, copts)
+			if err != nil {
+				return err
+			}
+
+			fn, err := quarterWaveAccessor(sel, identifier+"_"+sel, config.SampleScalarType, config.SamplesPerCycle, 2*bl.sampleOffset(&config)*scale)
+			if err != nil {
+				return err
+			}
+			renderer.AddFunction(r, identifier+"_"+sel+"_read", fn)
 		}
+	}
 
-		squares := make([][]float64, 0, numOctaves)
-		triangles := make([][]float64, 0, numOctaves)
-		sawtooths := make([][]float64, 0, numOctaves)
+	if slt.IsSelected("pulse") {
+		rows := make([][]float64, 0, len(config.PulseDutyCycles))
+		for _, duty := range config.PulseDutyCycles {
+			rows = append(rows, bl.scale(&config, pulseWaveform(duty, config.SamplesPerCycle)))
+		}
+		if err := bl.addRows(r, &config, copts, identifier, "pulse", rows); err != nil {
+			return err
+		}
+	}
+
+	if slt.IsSelected("blsquare") || slt.IsSelected("bltriangle") || slt.IsSelected("blsawtooth") || slt.IsSelected("blpulse") {
+		numTables, err := bl.numTables(&config)
+		if err != nil {
+			return err
+		}
+
+		squares := make([][]float64, 0, numTables)
+		triangles := make([][]float64, 0, numTables)
+		sawtooths := make([][]float64, 0, numTables)
+		pulses := make([][][]float64, len(config.PulseDutyCycles))
 
-		for oct := 0; oct < numOctaves; oct++ {
-			freq := wavetableFrequency(oct, a4Freq)
+		for row := 0; row < numTables; row++ {
+			freq := bl.tableFrequency(&config, row)
 			period := *config.SampleRate / freq
 			harmonics := float64(int(period))
 			if math.Mod(harmonics, 2) == 0 {
@@ -177,7 +434,7 @@ func (bl *Wavetables) Render(r renderer.Renderer, identifier string, dreg *datar
 				}
 			}
 
-			if slt.IsSelected("blsawtooth") {
+			if slt.IsSelected("blsawtooth") || slt.IsSelected("blpulse") {
 				sawtooth := make([]float64, 0, config.SamplesPerCycle)
 				v := 0.
 				for i := 0; i < config.SamplesPerCycle; i++ {
@@ -189,44 +446,184 @@ func (bl *Wavetables) Render(r renderer.Renderer, identifier string, dreg *datar
 					}
 					sawtooth = append(sawtooth, -v)
 				}
-				sawtooths = append(sawtooths, bl.fixWavetable(&config, sawtooth))
+
+				if slt.IsSelected("blsawtooth") {
+					sawtooths = append(sawtooths, bl.fixWavetable(&config, sawtooth))
+				}
+
+				if slt.IsSelected("blpulse") {
+					for i, duty := range config.PulseDutyCycles {
+						pulses[i] = append(pulses[i], bl.fixWavetable(&config, pulseFromSawtooth(sawtooth, duty)))
+					}
+				}
 			}
 		}
 
 		if slt.IsSelected("blsquare") {
-			rv := make([]any, 0, numOctaves)
-			for _, wt := range squares {
-				v, err := convert.Slice(wt, config.SampleScalarType)
-				if err != nil {
-					return err
-				}
-				rv = append(rv, v)
+			if err := bl.addRows(r, &config, copts, identifier, "blsquare", squares); err != nil {
+				return err
 			}
-			r.AddData(identifier+"_blsquare", rv, config.DataAttributes, nil)
 		}
 
 		if slt.IsSelected("bltriangle") {
-			rv := make([]any, 0, numOctaves)
-			for _, wt := range triangles {
-				v, err := convert.Slice(wt, config.SampleScalarType)
-				if err != nil {
-					return err
-				}
-				rv = append(rv, v)
+			if err := bl.addRows(r, &config, copts, identifier, "bltriangle", triangles); err != nil {
+				return err
 			}
-			r.AddData(identifier+"_bltriangle", rv, config.DataAttributes, nil)
 		}
 
 		if slt.IsSelected("blsawtooth") {
-			rv := make([]any, 0, numOctaves)
-			for _, wt := range sawtooths {
-				v, err := convert.Slice(wt, config.SampleScalarType)
-				if err != nil {
-					return err
+			if err := bl.addRows(r, &config, copts, identifier, "blsawtooth", sawtooths); err != nil {
+				return err
+			}
+		}
+
+		if slt.IsSelected("blpulse") {
+			if err := bl.addFrames(r, &config, copts, identifier, "blpulse", pulses); err != nil {
+				return err
+			}
+		}
+	}
+
+	if slt.IsSelected("additive") {
+		if len(config.AdditivePhases) > len(config.AdditiveAmplitudes) {
+			return errors.New("wavetables: additive_phases must not have more elements than additive_amplitudes")
+		}
+
+		numTables, err := bl.numTables(&config)
+		if err != nil {
+			return err
+		}
+
+		rows := make([][]float64, 0, numTables)
+		for row := 0; row < numTables; row++ {
+			wt := additive(config.SamplesPerCycle, config.AdditiveAmplitudes, config.AdditivePhases, bl.maxHarmonic(&config, row))
+			rows = append(rows, bl.normalizeWavetable(&config, wt))
+		}
+		if err := bl.addRows(r, &config, copts, identifier, "additive", rows); err != nil {
+			return err
+		}
+	}
+
+	if slt.IsSelected("wav") {
+		cycle, err := wav.Read(*config.WavFile)
+		if err != nil {
+			return err
+		}
+		if len(cycle) < 2 {
+			return fmt.Errorf("wavetables: wav file must have at least 2 samples: %s", *config.WavFile)
+		}
+
+		numTables, err := bl.numTables(&config)
+		if err != nil {
+			return err
+		}
+
+		spec := spectrum(cycle)
+		rows := make([][]float64, 0, numTables)
+		for row := 0; row < numTables; row++ {
+			rows = append(rows, bl.normalizeWavetable(&config, synthesize(spec, config.SamplesPerCycle, bl.maxHarmonic(&config, row))))
+		}
+		if err := bl.addRows(r, &config, copts, identifier, "wav", rows); err != nil {
+			return err
+		}
+	}
+
+	if slt.IsSelected("morph") {
+		frames, err := bl.morphFrames(&config)
+		if err != nil {
+			return err
+		}
+
+		if config.MorphBandlimited == nil || !*config.MorphBandlimited {
+			if err := bl.addRows(r, &config, copts, identifier, "morph", frames); err != nil {
+				return err
+			}
+		} else {
+			if config.SampleRate == nil {
+				return errors.New("wavetables: sample_rate is required by morph_bandlimited")
+			}
+
+			numTables, err := bl.numTables(&config)
+			if err != nil {
+				return err
+			}
+
+			blframes := make([][][]float64, 0, len(frames))
+			for _, frame := range frames {
+				spec := spectrum(frame)
+				rows := make([][]float64, 0, numTables)
+				for row := 0; row < numTables; row++ {
+					rows = append(rows, bl.normalizeWavetable(&config, synthesize(spec, config.SamplesPerCycle, bl.maxHarmonic(&config, row))))
 				}
-				rv = append(rv, v)
+				blframes = append(blframes, rows)
+			}
+			if err := bl.addFrames(r, &config, copts, identifier, "morph", blframes); err != nil {
+				return err
+			}
+		}
+	}
+
+	if slt.IsSelected("bl_note_table") || slt.IsSelected("bl_crossfade") {
+		rows, weights, err := bl.noteTable(&config)
+		if err != nil {
+			return err
+		}
+
+		if slt.IsSelected("bl_note_table") {
+			r.AddData(identifier+"_bl_note_table", rows, config.DataAttributes, nil)
+		}
+
+		if slt.IsSelected("bl_crossfade") {
+			wopts := copts.WithFractionalBitWidth(config.BlCrossfadeFractionalBitWidth)
+			w, err := convert.Slice(weights, *config.BlCrossfadeScalarType, wopts.WithIdentifier(identifier+"_bl_crossfade"))
+			if err != nil {
+				return err
+			}
+			r.AddData(identifier+"_bl_crossfade", renderer.Typed{Type: *config.BlCrossfadeScalarType, Value: w}, config.DataAttributes, nil)
+			if err := quantization.Report(r, config.QuantizationReport, identifier+"_bl_crossfade", *config.BlCrossfadeScalarType, wopts, weights, w); err != nil {
+				return err
+			}
+		}
+	}
+
+	if slt.IsSelected("minblep") || slt.IsSelected("blamp") || slt.IsSelected("polyblep") {
+		oversampling := blepOversampling
+		if config.BlepOversampling != nil {
+			if *config.BlepOversampling < 1 {
+				return errors.New("wavetables: blep_oversampling must be >= 1")
+			}
+			oversampling = *config.BlepOversampling
+		}
+
+		zeroCrossings := blepZeroCrossings
+		if config.BlepZeroCrossings != nil {
+			if *config.BlepZeroCrossings < 1 {
+				return errors.New("wavetables: blep_zero_crossings must be >= 1")
+			}
+			zeroCrossings = *config.BlepZeroCrossings
+		}
+
+		r.AddMacro(identifier+"_blep_oversampling", oversampling, false, false)
+		if slt.IsSelected("minblep") || slt.IsSelected("blamp") {
+			r.AddMacro(identifier+"_blep_zero_crossings", zeroCrossings, false, false)
+		}
+
+		if slt.IsSelected("minblep") {
+			if err := bl.addRawTable(r, &config, copts, identifier, "minblep", bl.scale(&config, minBLEP(oversampling, zeroCrossings))); err != nil {
+				return err
+			}
+		}
+
+		if slt.IsSelected("blamp") {
+			if err := bl.addRawTable(r, &config, copts, identifier, "blamp", bl.scale(&config, blamp(oversampling, zeroCrossings))); err != nil {
+				return err
+			}
+		}
+
+		if slt.IsSelected("polyblep") {
+			if err := bl.addRawTable(r, &config, copts, identifier, "polyblep", bl.scale(&config, polyBLEP(oversampling))); err != nil {
+				return err
 			}
-			r.AddData(identifier+"_blsawtooth", rv, config.DataAttributes, nil)
 		}
 	}
 
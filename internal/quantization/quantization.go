@@ -0,0 +1,163 @@
+package quantization
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+
+	"rafaelmartins.com/p/synth-datagen/internal/convert"
+	"rafaelmartins.com/p/synth-datagen/internal/renderer"
+)
+
+type Stats struct {
+	MaxError float64
+	RMSError float64
+	SNR      float64
+	ENOB     float64
+}
+
+func flatten(val reflect.Value, rv []float64) ([]float64, error) {
+	if val.Kind() == reflect.Interface {
+		val = reflect.ValueOf(val.Interface())
+	}
+
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			var err error
+			rv, err = flatten(val.Index(i), rv)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return rv, nil
+
+	case reflect.Struct:
+		for _, field := range reflect.VisibleFields(val.Type()) {
+			if !field.IsExported() {
+				continue
+			}
+			var err error
+			rv, err = flatten(val.FieldByIndex(field.Index), rv)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return rv, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return append(rv, float64(val.Int())), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return append(rv, float64(val.Uint())), nil
+
+	case reflect.Float32, reflect.Float64:
+		return append(rv, val.Float()), nil
+	}
+
+	return nil, fmt.Errorf("quantization: unsupported value type: %s", val.Type())
+}
+
+// New compares the source data of a table with its converted values, that were multiplied
+// by scale before the conversion. Both are walked in the same order, so they must have the
+// same shape.
+func New(src any, dst any, scale float64) (*Stats, error) {
+	if src == nil || dst == nil {
+		return nil, errors.New("quantization: got nil")
+	}
+
+	s, err := flatten(reflect.ValueOf(src), nil)
+	if err != nil {
+		return nil, err
+	}
+	d, err := flatten(reflect.ValueOf(dst), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(s) != len(d) {
+		return nil, fmt.Errorf("quantization: source and converted data sizes differ: %d != %d", len(s), len(d))
+	}
+	if len(s) == 0 {
+		return nil, errors.New("quantization: empty data")
+	}
+
+	// the signal power excludes the dc component, so that offset tables (e.g. unsigned
+	// samples or positive envelopes) don't report a higher snr for the same error.
+	mean := 0.
+	for _, v := range s {
+		mean += v
+	}
+	mean /= float64(len(s))
+
+	rv := &Stats{}
+	signal := 0.
+	noise := 0.
+	for i := range s {
+		e := d[i] - s[i]*scale
+		rv.MaxError = max(rv.MaxError, math.Abs(e))
+		signal += (s[i] - mean) * (s[i] - mean) * scale * scale
+		noise += e * e
+	}
+	rv.RMSError = math.Sqrt(noise / float64(len(s)))
+	rv.SNR = math.Inf(1)
+	if noise > 0 {
+		rv.SNR = 10 * math.Log10(signal/noise)
+	}
+	rv.ENOB = (rv.SNR - 1.76) / 6.02
+	return rv, nil
+}
+
+func (s *Stats) String() string {
+	return fmt.Sprintf("max error %.4f, rms error %.4f, snr %.2f dB, %.2f effective bits", s.MaxError, s.RMSError, s.SNR, s.ENOB)
+}
+
+func round(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
+
+// Report computes the quantization error of the table and reports it according to mode:
+// "none" (default) does nothing, "log" only logs the statistics, "comments" also adds them
+// as a comment before the table and "macros" also defines them as macros.
+func Report(r renderer.Renderer, mode *string, identifier string, to string, opts *convert.Options, src any, dst any) error {
+	m := "none"
+	if mode != nil {
+		m = *mode
+	}
+
+	switch m {
+	case "none":
+		return nil
+	case "log", "comments", "macros":
+	default:
+		return fmt.Errorf("quantization: invalid report mode: %s", m)
+	}
+
+	scale, err := convert.Scale(to, opts)
+	if err != nil {
+		return err
+	}
+
+	stats, err := New(src, dst, scale)
+	if err != nil {
+		return err
+	}
+	log.Printf("quantization: %s: %s", identifier, stats)
+
+	switch m {
+	case "comments":
+		renderer.AddComment(r, identifier, "quantization: "+stats.String())
+
+	case "macros":
+		r.AddMacro(identifier+"_quant_max_error", round(stats.MaxError), false, false)
+		r.AddMacro(identifier+"_quant_rms_error", round(stats.RMSError), false, false)
+
+		// lossless tables have infinite snr, that can't be represented without math.h
+		if !math.IsInf(stats.SNR, 0) {
+			r.AddMacro(identifier+"_quant_snr_db", round(stats.SNR), false, false)
+			r.AddMacro(identifier+"_quant_enob", round(stats.ENOB), false, false)
+		}
+	}
+	return nil
+}
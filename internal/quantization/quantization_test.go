@@ -0,0 +1,91 @@
+package quantization
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("lossless", func(t *testing.T) {
+		s, err := New([]float64{1, 2, 3}, []int8{1, 2, 3}, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.MaxError != 0 || s.RMSError != 0 || !math.IsInf(s.SNR, 1) {
+			t.Errorf("unexpected stats: %+v", s)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		s, err := New([][]float64{{1.5, -2}, {3, 4.5}}, []any{[]int8{1, -2}, []int8{3, 4}}, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.MaxError != 0.5 {
+			t.Errorf("unexpected max error: %f", s.MaxError)
+		}
+		if s.RMSError != math.Sqrt(0.125) {
+			t.Errorf("unexpected rms error: %f", s.RMSError)
+		}
+		// the mean of the source is 1.75.
+		snr := 10 * math.Log10((0.25*0.25+3.75*3.75+1.25*1.25+2.75*2.75)/0.5)
+		if math.Abs(s.SNR-snr) > 1e-9 {
+			t.Errorf("unexpected snr: %f, want %f", s.SNR, snr)
+		}
+		if math.Abs(s.ENOB-(snr-1.76)/6.02) > 1e-9 {
+			t.Errorf("unexpected enob: %f", s.ENOB)
+		}
+	})
+
+	t.Run("dc", func(t *testing.T) {
+		s, err := New([]float64{1.5, -2, 3, 4.5}, []int8{1, -2, 3, 4}, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o, err := New([]float64{101.5, 98, 103, 104.5}, []int8{101, 98, 103, 104}, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(s.SNR-o.SNR) > 1e-9 {
+			t.Errorf("snr changed by a dc offset: %f, want %f", o.SNR, s.SNR)
+		}
+	})
+
+	t.Run("structs", func(t *testing.T) {
+		type src struct {
+			A float64
+			B float64
+		}
+		type dst struct {
+			A int16
+			B int16
+		}
+		s, err := New([]src{{1.25, 2}}, []dst{{1, 2}}, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.MaxError != 0.25 {
+			t.Errorf("unexpected max error: %f", s.MaxError)
+		}
+	})
+
+	t.Run("scale", func(t *testing.T) {
+		s, err := New([]float64{0.5, -0.25}, []int16{16384, -8193}, 32768)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.MaxError != 1 {
+			t.Errorf("unexpected max error: %f", s.MaxError)
+		}
+	})
+
+	t.Run("size_mismatch", func(t *testing.T) {
+		_, err := New([]float64{1, 2}, []int8{1}, 1)
+		if err == nil {
+			t.Fatal("expected error for size mismatch")
+		}
+		if err.Error() != "quantization: source and converted data sizes differ: 2 != 1" {
+			t.Errorf("unexpected error message: %q", err.Error())
+		}
+	})
+}
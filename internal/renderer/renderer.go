@@ -8,3 +8,70 @@ type Renderer interface {
 	AddData(identifier string, value any, attributes []string, strWidth *int)
 	Write(w io.Writer) error
 }
+
+// CommentRenderer is implemented by renderers that attach comments to data.
+type CommentRenderer interface {
+	AddComment(identifier string, comment string)
+}
+
+// PaddingRenderer is implemented by renderers that handle the padding elements at the end
+// of the last dimension of data.
+type PaddingRenderer interface {
+	AddPadding(identifier string, padding int)
+}
+
+// FunctionRenderer is implemented by renderers that emit functions.
+type FunctionRenderer interface {
+	AddFunction(identifier string, code string)
+}
+
+// FilterResponseRenderer is implemented by renderers that plot the frequency response of
+// filter coefficient tables.
+type FilterResponseRenderer interface {
+	AddFilterResponse(identifier string, value any, sampleRate float64, scale float64)
+}
+
+// AddComment attaches a comment to the data with the given identifier, if supported by the
+// renderer.
+func AddComment(r Renderer, identifier string, comment string) {
+	if cr, ok := r.(CommentRenderer); ok {
+		cr.AddComment(identifier, comment)
+	}
+}
+
+// AddPadding marks the last padding elements of the data with the given identifier, if
+// supported by the renderer.
+func AddPadding(r Renderer, identifier string, padding int) {
+	if pr, ok := r.(PaddingRenderer); ok {
+		pr.AddPadding(identifier, padding)
+	}
+}
+
+// AddFunction adds a function, if supported by the renderer.
+func AddFunction(r Renderer, identifier string, code string) {
+	if fr, ok := r.(FunctionRenderer); ok {
+		fr.AddFunction(identifier, code)
+	}
+}
+
+// AddFilterResponse adds the frequency response of a filter coefficient table, if
+// supported by the renderer.
+func AddFilterResponse(r Renderer, identifier string, value any, sampleRate float64, scale float64) {
+	if fr, ok := r.(FilterResponseRenderer); ok {
+		fr.AddFilterResponse(identifier, value, sampleRate, scale)
+	}
+}
+
+// Jagged is a list of rows that is rendered as a jagged array, even if all the rows have
+// the same length. Slices of rows with different lengths are always rendered as jagged
+// arrays.
+type Jagged []any
+
+// Typed is a value converted to the C type named Type. Values of Q format types are
+// declared along with macros describing their format. StructType optionally names the
+// struct type of tables of structs, so that tables with the same layout share it.
+type Typed struct {
+	Type       string
+	StructType string
+	Value      any
+}
@@ -0,0 +1,143 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+const (
+	formatPCM        = 0x0001
+	formatFloat      = 0x0003
+	formatExtensible = 0xfffe
+)
+
+type format struct {
+	AudioFormat   uint16
+	Channels      uint16
+	SampleRate    uint32
+	ByteRate      uint32
+	BlockAlign    uint16
+	BitsPerSample uint16
+}
+
+// Decode reads a WAV file and returns its samples as values in the [-1, 1] range, with all
+// the channels mixed down to mono. 8, 16, 24 and 32 bits PCM and 32 bits float formats are
+// supported.
+func Decode(r io.Reader) ([]float64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, errors.New("wav: not a RIFF/WAVE file")
+	}
+
+	var (
+		fmtc    *format
+		samples []byte
+	)
+	for data = data[12:]; len(data) >= 8; {
+		id := string(data[0:4])
+		size := int(binary.LittleEndian.Uint32(data[4:8]))
+		data = data[8:]
+		if size > len(data) {
+			return nil, fmt.Errorf("wav: truncated chunk: %q", id)
+		}
+		chunk := data[:size]
+
+		// chunks are word aligned
+		data = data[min(size+size%2, len(data)):]
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, errors.New("wav: invalid fmt chunk")
+			}
+			fmtc = &format{}
+			if err := binary.Read(bytes.NewReader(chunk), binary.LittleEndian, fmtc); err != nil {
+				return nil, err
+			}
+			if fmtc.AudioFormat == formatExtensible {
+				if size < 26 {
+					return nil, errors.New("wav: invalid extensible fmt chunk")
+				}
+				// first 2 bytes of the sub format guid are the actual format
+				fmtc.AudioFormat = binary.LittleEndian.Uint16(chunk[24:26])
+			}
+
+		case "data":
+			samples = chunk
+		}
+	}
+
+	if fmtc == nil {
+		return nil, errors.New("wav: fmt chunk not found")
+	}
+	if samples == nil {
+		return nil, errors.New("wav: data chunk not found")
+	}
+	if fmtc.Channels == 0 {
+		return nil, errors.New("wav: invalid number of channels: 0")
+	}
+
+	var decode func(b []byte) float64
+	switch {
+	case fmtc.AudioFormat == formatPCM && fmtc.BitsPerSample == 8:
+		decode = func(b []byte) float64 {
+			return (float64(b[0]) - 128) / 128
+		}
+
+	case fmtc.AudioFormat == formatPCM && fmtc.BitsPerSample == 16:
+		decode = func(b []byte) float64 {
+			return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+		}
+
+	case fmtc.AudioFormat == formatPCM && fmtc.BitsPerSample == 24:
+		decode = func(b []byte) float64 {
+			return float64(int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24)>>8) / (1 << 23)
+		}
+
+	case fmtc.AudioFormat == formatPCM && fmtc.BitsPerSample == 32:
+		decode = func(b []byte) float64 {
+			return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+		}
+
+	case fmtc.AudioFormat == formatFloat && fmtc.BitsPerSample == 32:
+		decode = func(b []byte) float64 {
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		}
+
+	default:
+		return nil, fmt.Errorf("wav: unsupported format: 0x%04x, %d bits", fmtc.AudioFormat, fmtc.BitsPerSample)
+	}
+
+	width := int(fmtc.BitsPerSample) / 8
+	frame := width * int(fmtc.Channels)
+
+	rv := make([]float64, 0, len(samples)/frame)
+	for i := 0; i+frame <= len(samples); i += frame {
+		v := 0.
+		for c := 0; c < int(fmtc.Channels); c++ {
+			v += decode(samples[i+c*width:])
+		}
+		rv = append(rv, v/float64(fmtc.Channels))
+	}
+	return rv, nil
+}
+
+// Read reads a WAV file from the file system. See Decode.
+func Read(name string) ([]float64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Decode(f)
+}
@@ -0,0 +1,110 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func wavFile(audioFormat uint16, channels uint16, bits uint16, extensible bool, data []byte) []byte {
+	fmtc := &bytes.Buffer{}
+	f := format{
+		AudioFormat:   audioFormat,
+		Channels:      channels,
+		SampleRate:    48000,
+		ByteRate:      48000 * uint32(channels) * uint32(bits) / 8,
+		BlockAlign:    channels * bits / 8,
+		BitsPerSample: bits,
+	}
+	if extensible {
+		f.AudioFormat = formatExtensible
+	}
+	binary.Write(fmtc, binary.LittleEndian, f)
+	if extensible {
+		binary.Write(fmtc, binary.LittleEndian, []uint16{22, bits})
+		binary.Write(fmtc, binary.LittleEndian, uint32(0))
+		binary.Write(fmtc, binary.LittleEndian, audioFormat)
+		fmtc.Write(make([]byte, 14))
+	}
+
+	body := &bytes.Buffer{}
+	body.WriteString("WAVE")
+	body.WriteString("fmt ")
+	binary.Write(body, binary.LittleEndian, uint32(fmtc.Len()))
+	body.Write(fmtc.Bytes())
+	body.WriteString("LIST")
+	binary.Write(body, binary.LittleEndian, uint32(3))
+	body.Write([]byte{1, 2, 3, 0})
+	body.WriteString("data")
+	binary.Write(body, binary.LittleEndian, uint32(len(data)))
+	body.Write(data)
+
+	rv := &bytes.Buffer{}
+	rv.WriteString("RIFF")
+	binary.Write(rv, binary.LittleEndian, uint32(body.Len()))
+	rv.Write(body.Bytes())
+	return rv.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	f32 := &bytes.Buffer{}
+	binary.Write(f32, binary.LittleEndian, []float32{0.5, -0.25, 1})
+
+	tests := []struct {
+		name     string
+		file     []byte
+		expected []float64
+	}{
+		{"pcm8", wavFile(formatPCM, 1, 8, false, []byte{0x80, 0xc0, 0x00}), []float64{0, 0.5, -1}},
+		{"pcm16", wavFile(formatPCM, 1, 16, false, []byte{0x00, 0x40, 0x00, 0x80, 0xff, 0x7f}), []float64{0.5, -1, 32767. / 32768}},
+		{"pcm24", wavFile(formatPCM, 1, 24, false, []byte{0x00, 0x00, 0x40, 0x00, 0x00, 0xc0}), []float64{0.5, -0.5}},
+		{"pcm32", wavFile(formatPCM, 1, 32, false, []byte{0x00, 0x00, 0x00, 0x40, 0x00, 0x00, 0x00, 0x80}), []float64{0.5, -1}},
+		{"float32", wavFile(formatFloat, 1, 32, false, f32.Bytes()), []float64{0.5, -0.25, 1}},
+		{"float32_extensible", wavFile(formatFloat, 1, 32, true, f32.Bytes()), []float64{0.5, -0.25, 1}},
+		{"pcm16_stereo", wavFile(formatPCM, 2, 16, false, []byte{0x00, 0x40, 0x00, 0x00, 0x00, 0xc0, 0x00, 0xc0}), []float64{0.25, -0.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Decode(bytes.NewReader(tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, result)
+			}
+			for i := range result {
+				if math.Abs(result[i]-tt.expected[i]) > 1e-9 {
+					t.Errorf("expected %v, got %v", tt.expected, result)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     []byte
+		expected string
+	}{
+		{"not_wave", []byte("RIFF\x04\x00\x00\x00AVI "), "wav: not a RIFF/WAVE file"},
+		{"unsupported", wavFile(formatPCM, 1, 12, false, []byte{0, 0}), "wav: unsupported format: 0x0001, 12 bits"},
+		{"float64", wavFile(formatFloat, 1, 64, false, make([]byte, 8)), "wav: unsupported format: 0x0003, 64 bits"},
+		{"no_data", []byte("RIFF\x04\x00\x00\x00WAVE"), "wav: fmt chunk not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(bytes.NewReader(tt.file))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("unexpected error message: %q", err.Error())
+			}
+		})
+	}
+}
@@ -40,7 +40,7 @@ func main() {
 	conf, err := config.New(*oConfig)
 	check(err)
 
-	modules.SetGlobalParameters(conf.GlobalParameters)
+	modules.SetGlobalParameters(conf.GlobalParameters, filepath.Dir(*oConfig))
 
 	for _, out := range conf.Outputs {
 		var (
@@ -55,7 +55,11 @@ func main() {
 			rndr = charts.New(filepath.Base(out.HeaderOutput))
 		} else {
 			outfile = filepath.Join(*oOutput, out.HeaderOutput)
-			rndr = codegen.NewHeader()
+			h := codegen.NewHeader()
+			if st := out.StructTypes; st != nil {
+				h.SetStructTypes(st.NameTemplate, st.FieldOrder, st.Attributes)
+			}
+			rndr = h
 		}
 
 		log.Printf("Generating %q ...", outfile)
@@ -65,11 +69,11 @@ func main() {
 		}
 
 		for _, mac := range out.Macros {
-			rndr.AddMacro(mac.Identifier, mac.Value, mac.Hex, mac.Raw)
+			rndr.AddMacro(mac.Identifier, renderer.Typed{Type: mac.Type, Value: mac.Value}, mac.Hex, mac.Raw)
 		}
 
 		for _, v := range out.Variables {
-			rndr.AddData(v.Identifier, v.Value, v.Attributes, v.StringWidth)
+			rndr.AddData(v.Identifier, renderer.Typed{Type: v.Type, Value: v.Value}, v.Attributes, v.StringWidth)
 		}
 
 		for _, mod := range out.Modules {